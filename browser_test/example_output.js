@@ -73,13 +73,13 @@ var Person = /** @class */ (function () {
             return a;
         }
         if (a.slice) {
-            return a.map(function (elem) { return _this.convertValues(elem, classs); });
+            return a.map(function (elem) { return _this.convertValues(elem, classs, asMap); });
         }
         else if ("object" === typeof a) {
             if (asMap) {
                 for (var _i = 0, _a = Object.keys(a); _i < _a.length; _i++) {
                     var key = _a[_i];
-                    a[key] = new classs(a[key]);
+                    a[key] = _this.convertValues(a[key], classs);
                 }
                 return a;
             }
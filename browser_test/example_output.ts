@@ -51,7 +51,7 @@ export class Person {
     personal_info: PersonalInfo;
     nicknames: string[];
     addresses: Address[];
-    address?: Address;
+    address?: Address | null;
     metadata: {[key:string]:string};
     friends: Person[];
 
@@ -75,11 +75,11 @@ export class Person {
 	        return a;
 	    }
 	    if (a.slice) {
-	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	        return (a as any[]).map(elem => this.convertValues(elem, classs, asMap));
 	    } else if ("object" === typeof a) {
 	        if (asMap) {
 	            for (const key of Object.keys(a)) {
-	                a[key] = new classs(a[key]);
+	                a[key] = this.convertValues(a[key], classs);
 	            }
 	            return a;
 	        }
@@ -15,7 +15,7 @@ export interface Person {
     personal_info: PersonalInfo;
     nicknames: string[];
     addresses: Address[];
-    address?: Address;
+    address?: Address | null;
     metadata: {[key:string]:string};
     friends: Person[];
     //[Person:]
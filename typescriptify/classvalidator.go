@@ -0,0 +1,93 @@
+package typescriptify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// classValidatorDecorators returns the class-validator decorators (i.e. `@IsString()`) of the field, the field type
+// must be dereferenced. The used decorators are imported.
+func (t *TypeScriptify) classValidatorDecorators(typ reflect.Type, optional bool, opts TypeOptions) []string {
+	var decorators []string
+	if optional {
+		decorators = append(decorators, "IsOptional()")
+	}
+	if opts.TSType != "" || opts.TSTransform != "" {
+		if decorator := classValidatorTSTypeDecorator(opts.TSType); decorator != "" {
+			decorators = append(decorators, decorator)
+		}
+	} else {
+		decorators = append(decorators, t.classValidatorTypeDecorators(typ)...)
+	}
+
+	if t.usedDecorators == nil {
+		t.usedDecorators = map[string]bool{}
+	}
+	for n := range decorators {
+		t.usedDecorators[decorators[n][:strings.Index(decorators[n], "(")]] = true
+		decorators[n] = "@" + decorators[n]
+	}
+	return decorators
+}
+
+// classValidatorTSTypeDecorator returns the decorator for fields with a custom TypeScript type (empty if unknown).
+func classValidatorTSTypeDecorator(tsType string) string {
+	switch tsType {
+	case "string":
+		return "IsString()"
+	case "number":
+		return "IsNumber()"
+	case "boolean":
+		return "IsBoolean()"
+	case "Date":
+		return "IsDate()"
+	}
+	return ""
+}
+
+func (t *TypeScriptify) classValidatorTypeDecorators(typ reflect.Type) []string {
+	if _, isEnum := t.enums[typ]; isEnum {
+		if t.EnumStyle == EnumStyleConstEnum { // Not available at runtime
+			return nil
+		}
+		return []string{fmt.Sprintf("IsEnum(%s)", t.entityName(typ))}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return []string{"IsBoolean()"}
+	case reflect.String:
+		return []string{"IsString()"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return []string{"IsNumber()"}
+	case reflect.Struct:
+		return []string{"ValidateNested()"}
+	case reflect.Map:
+		return []string{"IsObject()"}
+	case reflect.Slice, reflect.Array:
+		if isByteSlice(typ) {
+			return []string{"IsString()"}
+		}
+		if elemType, _ := arrayElem(typ); elemType.Kind() == reflect.Struct {
+			return []string{"IsArray()", "ValidateNested({ each: true })"}
+		}
+		return []string{"IsArray()"}
+	}
+	return nil
+}
+
+// classValidatorImport returns the import of all the used class-validator decorators.
+func (t *TypeScriptify) classValidatorImport() string {
+	if len(t.usedDecorators) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range t.usedDecorators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("import { %s } from 'class-validator';\n", strings.Join(names, ", "))
+}
@@ -0,0 +1,165 @@
+package typescriptify
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OutputFormatter creates code in another language from the converted struct types, see ConvertWithFormatter().
+type OutputFormatter interface {
+	Format(types []OutputType) (string, error)
+}
+
+// OutputType is a converted struct type, in dependency order (types used in fields are before the type, unless
+// recursive).
+type OutputType struct {
+	Name   string
+	Type   reflect.Type
+	Fields []OutputField
+}
+
+// OutputField is a (JSON) field of a converted struct type.
+type OutputField struct {
+	Name          string       // JSON field name
+	Type          reflect.Type // Field type, pointers are dereferenced
+	Pointer       bool         // The field is a pointer (and can be null)
+	Optional      bool         // Pointer or omitempty
+	StringEncoded bool         // The (scalar) value is encoded as a JSON string (the `,string` json option)
+	TSType        string       // The TypeScript type (from ts_type or TSTyper) used instead of the field type, if not empty
+}
+
+// valueType returns the JSON type ("string", "number", "boolean" or "" for any value) of the fields which aren't typed
+// by their Golang type, string encoded fields and fields with a TypeScript type. The second result is false for other
+// fields.
+func (f OutputField) valueType() (string, bool) {
+	if f.StringEncoded {
+		return "string", true
+	}
+	if f.TSType == "" {
+		return "", false
+	}
+	switch tsType := strings.TrimSpace(f.TSType); tsType {
+	case "string", "number", "boolean":
+		return tsType, true
+	}
+	return "", true
+}
+
+// ConvertWithFormatter converts the types and formats them with the formatter (instead of creating TypeScript code).
+func (t *TypeScriptify) ConvertWithFormatter(formatter OutputFormatter) (string, error) {
+	types, err := t.outputTypes()
+	if err != nil {
+		return "", err
+	}
+	return formatter.Format(types)
+}
+
+// outputTypes returns the added struct types and the struct types used in their fields (without converting them to
+// TypeScript). The types used in the fields are before the type (unless recursive), like in the TypeScript code.
+func (t *TypeScriptify) outputTypes() ([]OutputType, error) {
+	t.entityTypes = map[string]reflect.Type{}
+	t.anonymousNames = map[reflect.Type]string{}
+	visited := map[reflect.Type]bool{}
+	var types []OutputType
+
+	var add func(typeOf reflect.Type, path []string) error
+	add = func(typeOf reflect.Type, path []string) error {
+		if visited[typeOf] || typeOf == timeType || t.importedTypes[typeOf] {
+			return nil
+		}
+		visited[typeOf] = true
+		if len(path) == 0 {
+			path = []string{structName(typeOf, t.anonymousNames)}
+		}
+		if t.Inheritance {
+			_, bases := embeddedStructs(typeOf)
+			for _, base := range bases {
+				if err := add(base, nil); err != nil {
+					return err
+				}
+			}
+		}
+
+		var fields []OutputField
+		for _, field := range t.orderFields(deepFields(typeOf)) {
+			isPtr := field.Type.Kind() == reflect.Ptr
+			if isPtr {
+				field.Type = field.Type.Elem()
+			}
+			field.Type = withContainerShapes(field.Type, t.containerTypes)
+			jsonFieldName := t.getJSONFieldName(field, isPtr)
+			if len(jsonFieldName) == 0 || jsonFieldName == "-" {
+				continue
+			}
+			opts := t.getFieldOptions(typeOf, field)
+			if kind, unsupported := unsupportedKind(field.Type); unsupported && opts.TSType == "" { // Skipped like in convertType()
+				if t.Strict {
+					return fmt.Errorf("cannot convert %s.%s: %s fields can't be represented in TypeScript (without a ts_type)", typeOf.Name(), field.Name, kind.String())
+				}
+				continue
+			}
+			if opts.TSType == "" && opts.TSTransform == "" {
+				if elem, _ := containedStruct(field.Type); elem != nil {
+					fieldPath := append(append([]string{}, path...), field.Name)
+					if t.AnonymousStructNames && typeName(elem) == "" {
+						if _, found := t.anonymousNames[elem]; !found {
+							t.anonymousNames[elem] = strings.Join(fieldPath, "")
+						}
+					}
+					if err := add(elem, fieldPath); err != nil {
+						return err
+					}
+				}
+			}
+			fields = append(fields, OutputField{
+				Name:          strings.TrimSuffix(jsonFieldName, "?"),
+				Type:          field.Type,
+				Pointer:       isPtr,
+				Optional:      strings.HasSuffix(jsonFieldName, "?"),
+				StringEncoded: opts.TSType == "" && isStringEncoded(field),
+				TSType:        opts.TSType,
+			})
+		}
+
+		name := t.entityName(typeOf)
+		if err := t.registerEntityName(typeOf, name); err != nil {
+			return err
+		}
+		types = append(types, OutputType{Name: name, Type: typeOf, Fields: fields})
+		return nil
+	}
+
+	var roots []reflect.Type
+	for _, strctTyp := range t.structTypes {
+		roots = append(roots, strctTyp.Type)
+	}
+	for _, sum := range t.sumTypes {
+		roots = append(roots, sum.variants...)
+	}
+	for _, root := range roots {
+		if elem, _ := containedStruct(root); elem != nil {
+			if err := add(elem, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return types, nil
+}
+
+// outputTypeNames returns the names of the output struct types.
+func outputTypeNames(types []OutputType) map[reflect.Type]string {
+	names := map[reflect.Type]string{}
+	for _, typ := range types {
+		names[typ.Type] = typ.Name
+	}
+	return names
+}
+
+// outputTypeName returns the name of the (struct) type, the Golang type name if it isn't converted.
+func outputTypeName(names map[reflect.Type]string, typ reflect.Type) string {
+	if name, found := names[typ]; found {
+		return name
+	}
+	return typeName(typ)
+}
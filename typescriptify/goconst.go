@@ -0,0 +1,30 @@
+package typescriptify
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// ConvertToGoConst converts the types and returns a Go source file (in the package) with the TypeScript code (as
+// written by ConvertToWriter()) in a string constant, i.e. to embed it in a Go binary.
+func (t *TypeScriptify) ConvertToGoConst(packageName, constName string) (string, error) {
+	var code bytes.Buffer
+	if err := t.ConvertToWriter(&code, nil); err != nil {
+		return "", err
+	}
+
+	literal := "`" + code.String() + "`"
+	if strings.ContainsAny(code.String(), "`\r") { // Can't be in a raw string literal
+		literal = strconv.Quote(code.String())
+	}
+	src := fmt.Sprintf("// Code generated by typescriptify. DO NOT EDIT.\n\npackage %s\n\n// %s is the generated TypeScript code.\nconst %s = %s\n", packageName, constName, constName, literal)
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
@@ -0,0 +1,134 @@
+package typescriptify
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	// graphQLJSONScalar is used for types without a GraphQL equivalent (maps, interfaces and json.RawMessage).
+	graphQLJSONScalar = "JSON"
+	// graphQLInt64Scalar is used for integers which don't fit into the (32-bit) GraphQL Int.
+	graphQLInt64Scalar = "Int64"
+)
+
+// graphQLNameRegexp matches the valid GraphQL names.
+var graphQLNameRegexp = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// ConvertToGraphQL converts the types and returns GraphQL SDL type definitions of them (instead of the TypeScript
+// code). Fields which are not pointers or omitempty are required (`!`), maps and interfaces are converted to a `JSON`
+// scalar, and 64-bit integers to an `Int64` scalar. Converting types without fields, or with JSON names which aren't
+// valid GraphQL names, is an error.
+func (t *TypeScriptify) ConvertToGraphQL() (string, error) {
+	return t.ConvertWithFormatter(GraphQLFormatter{Indent: t.Indent})
+}
+
+// GraphQLFormatter creates GraphQL SDL type definitions.
+type GraphQLFormatter struct {
+	Indent string
+}
+
+var _ OutputFormatter = GraphQLFormatter{}
+
+func (f GraphQLFormatter) Format(types []OutputType) (string, error) {
+	names := outputTypeNames(types)
+	scalars := map[string]bool{}
+	var definitions []string
+	for _, typ := range types {
+		if len(typ.Fields) == 0 {
+			return "", fmt.Errorf("cannot convert %s: GraphQL types must have at least one field", typ.Type.String())
+		}
+		result := fmt.Sprintf("type %s {\n", typ.Name)
+		for _, field := range typ.Fields {
+			if !graphQLNameRegexp.MatchString(field.Name) {
+				return "", fmt.Errorf("cannot convert %s.%s: it isn't a valid GraphQL name", typ.Type.String(), field.Name)
+			}
+			graphQLType, err := graphQLFieldType(names, scalars, field)
+			if err != nil {
+				return "", fmt.Errorf("cannot convert %s.%s: %s", typ.Type.String(), field.Name, err.Error())
+			}
+			if !field.Optional {
+				graphQLType += "!"
+			}
+			result += fmt.Sprintf("%s%s: %s\n", f.Indent, field.Name, graphQLType)
+		}
+		result += "}"
+		definitions = append(definitions, result)
+	}
+	var scalarDefinitions []string
+	for _, scalar := range []string{graphQLInt64Scalar, graphQLJSONScalar} {
+		if scalars[scalar] {
+			scalarDefinitions = append(scalarDefinitions, "scalar "+scalar)
+		}
+	}
+	if len(scalarDefinitions) > 0 {
+		definitions = append([]string{strings.Join(scalarDefinitions, "\n")}, definitions...)
+	}
+
+	return strings.Join(definitions, "\n\n") + "\n", nil
+}
+
+// graphQLFieldType returns the (nullable) GraphQL type of the field, string encoded fields and fields with a TypeScript
+// type are converted by their JSON type.
+func graphQLFieldType(names map[reflect.Type]string, scalars map[string]bool, field OutputField) (string, error) {
+	valueType, found := field.valueType()
+	if !found {
+		return graphQLType(names, scalars, field.Type)
+	}
+	switch valueType {
+	case "string":
+		return "String", nil
+	case "number":
+		return "Float", nil
+	case "boolean":
+		return "Boolean", nil
+	}
+	scalars[graphQLJSONScalar] = true
+	return graphQLJSONScalar, nil
+}
+
+// graphQLType returns the (nullable) GraphQL type of the Golang type, the custom scalars used are added to scalars.
+func graphQLType(names map[reflect.Type]string, scalars map[string]bool, typ reflect.Type) (string, error) {
+	if typ == timeType {
+		return "String", nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return graphQLType(names, scalars, typ.Elem())
+	case reflect.Slice, reflect.Array:
+		if typ == rawMessageType {
+			scalars[graphQLJSONScalar] = true
+			return graphQLJSONScalar, nil
+		}
+		if isByteSlice(typ) {
+			return "String", nil
+		}
+		elem, err := graphQLType(names, scalars, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		if typ.Elem().Kind() != reflect.Ptr {
+			elem += "!"
+		}
+		return "[" + elem + "]", nil
+	case reflect.Struct:
+		return outputTypeName(names, typ), nil
+	case reflect.Map, reflect.Interface:
+		scalars[graphQLJSONScalar] = true
+		return graphQLJSONScalar, nil
+	case reflect.Bool:
+		return "Boolean", nil
+	case reflect.String:
+		return "String", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "Int", nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		scalars[graphQLInt64Scalar] = true
+		return graphQLInt64Scalar, nil
+	case reflect.Float32, reflect.Float64:
+		return "Float", nil
+	}
+	return "", fmt.Errorf("no GraphQL type for %s", typ.String())
+}
@@ -0,0 +1,161 @@
+package typescriptify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// jsonSchemaDialect is the JSON Schema version of the created documents.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ConvertToJSONSchema converts the types and returns a JSON Schema document (instead of the TypeScript code). Every
+// struct is an object schema in `$defs`, nested structs are `$ref`s to them. Fields which are not pointers or
+// omitempty are required, and pointers, slices and maps (also as elements) can be null.
+func (t *TypeScriptify) ConvertToJSONSchema() (string, error) {
+	return t.ConvertWithFormatter(JSONSchemaFormatter{Indent: t.Indent})
+}
+
+// JSONSchemaFormatter creates a JSON Schema document with the struct types in `$defs`.
+type JSONSchemaFormatter struct {
+	Indent string
+}
+
+var _ OutputFormatter = JSONSchemaFormatter{}
+
+func (f JSONSchemaFormatter) Format(types []OutputType) (string, error) {
+	names := outputTypeNames(types)
+	defs := orderedObject{}
+	for _, typ := range types {
+		properties := orderedObject{}
+		required := []string{}
+		for _, field := range typ.Fields {
+			var schema orderedObject
+			if valueType, found := field.valueType(); found {
+				if valueType != "" {
+					schema = orderedObject{{"type", valueType}}
+				}
+			} else {
+				var err error
+				if schema, err = jsonSchema(names, field.Type); err != nil {
+					return "", fmt.Errorf("cannot convert %s.%s: %s", typ.Type.String(), field.Name, err.Error())
+				}
+			}
+			if field.Pointer {
+				schema = nullableSchema(schema)
+			}
+			properties = append(properties, keyValue{field.Name, schema})
+			if !field.Optional {
+				required = append(required, field.Name)
+			}
+		}
+		def := orderedObject{{"type", "object"}, {"properties", properties}}
+		if len(required) > 0 {
+			def = append(def, keyValue{"required", required})
+		}
+		defs = append(defs, keyValue{typ.Name, def})
+	}
+
+	document := orderedObject{{"$schema", jsonSchemaDialect}, {"$defs", defs}}
+	byts, err := json.MarshalIndent(document, "", f.Indent)
+	if err != nil {
+		return "", err
+	}
+	return string(byts) + "\n", nil
+}
+
+// jsonSchema returns the JSON Schema of the Golang type.
+func jsonSchema(names map[reflect.Type]string, typ reflect.Type) (orderedObject, error) {
+	if typ == timeType {
+		return orderedObject{{"type", "string"}, {"format", "date-time"}}, nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := jsonSchema(names, typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullableSchema(elem), nil
+	case reflect.Slice, reflect.Array:
+		if typ == rawMessageType {
+			return orderedObject{}, nil
+		}
+		if isByteSlice(typ) {
+			schema := orderedObject{{"type", "string"}, {"contentEncoding", "base64"}}
+			if typ.Kind() == reflect.Slice { // Nil slices are null
+				schema = nullableSchema(schema)
+			}
+			return schema, nil
+		}
+		elem, err := jsonSchema(names, typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := orderedObject{{"type", "array"}, {"items", elem}}
+		if typ.Kind() == reflect.Array {
+			return append(schema, keyValue{"minItems", typ.Len()}, keyValue{"maxItems", typ.Len()}), nil
+		}
+		return nullableSchema(schema), nil
+	case reflect.Map:
+		value, err := jsonSchema(names, typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullableSchema(orderedObject{{"type", "object"}, {"additionalProperties", value}}), nil
+	case reflect.Struct:
+		return orderedObject{{"$ref", "#/$defs/" + outputTypeName(names, typ)}}, nil
+	case reflect.Interface:
+		return orderedObject{}, nil // Any value
+	case reflect.Bool:
+		return orderedObject{{"type", "boolean"}}, nil
+	case reflect.String:
+		return orderedObject{{"type", "string"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return orderedObject{{"type", "integer"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return orderedObject{{"type", "number"}}, nil
+	}
+	return nil, fmt.Errorf("no JSON Schema type for %s", typ.String())
+}
+
+// nullableSchema returns the schema which also accepts null (unless it already does).
+func nullableSchema(schema orderedObject) orderedObject {
+	if len(schema) == 0 || (len(schema) == 1 && schema[0].key == "anyOf") { // Any value, or already nullable
+		return schema
+	}
+	return orderedObject{{"anyOf", []orderedObject{schema, {{"type", "null"}}}}}
+}
+
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// orderedObject is a JSON object which keeps the order of the keys (unlike maps), so that the properties are in the
+// order of the fields.
+type orderedObject []keyValue
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for n, kv := range o {
+		if n > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(kv.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
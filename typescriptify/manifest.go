@@ -0,0 +1,31 @@
+package typescriptify
+
+import "encoding/json"
+
+// ManifestField describes one field of a converted type.
+type ManifestField struct {
+	Name     string `json:"name"`
+	TSType   string `json:"tsType"`
+	Optional bool   `json:"optional"`
+	Kind     string `json:"kind"`
+}
+
+// ManifestType describes one converted type.
+type ManifestType struct {
+	Name   string          `json:"name"`
+	GoType string          `json:"goType"`
+	Fields []ManifestField `json:"fields"`
+}
+
+// Manifest is a machine readable description of all the converted types.
+type Manifest struct {
+	Types []ManifestType `json:"types"`
+}
+
+// ConvertToManifest converts the types and returns the JSON manifest of them (instead of the TypeScript code).
+func (t *TypeScriptify) ConvertToManifest() ([]byte, error) {
+	if _, err := t.Convert(nil); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(Manifest{Types: t.manifest}, "", "  ")
+}
@@ -0,0 +1,191 @@
+package typescriptify
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pythonKeywords can't be used as attribute names.
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true, "await": true,
+	"break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true, "import": true, "in": true,
+	"is": true, "lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true, "return": true,
+	"try": true, "while": true, "with": true, "yield": true,
+}
+
+var (
+	pythonIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][0-9A-Za-z_]*$`)
+	pythonInvalidRegexp    = regexp.MustCompile(`[^0-9A-Za-z_]`)
+)
+
+// ConvertToPythonDataclasses converts the types and returns Python `@dataclass` definitions of them (instead of the
+// TypeScript code).
+func (t *TypeScriptify) ConvertToPythonDataclasses() (string, error) {
+	return t.ConvertWithFormatter(PythonDataclassFormatter{Indent: t.Indent})
+}
+
+// PythonDataclassFormatter creates Python `@dataclass` definitions. Optional fields are `Optional[...]`, maps are
+// `Dict[...]`, slices are `List[...]` and interfaces are `Any`. JSON names which aren't valid attribute names (i.e.
+// `class` or `foo-bar`) are changed (to `class_` and `foo_bar`), with the JSON name in the field metadata.
+type PythonDataclassFormatter struct {
+	Indent string // Four spaces, if empty
+}
+
+var _ OutputFormatter = PythonDataclassFormatter{}
+
+func (f PythonDataclassFormatter) Format(types []OutputType) (string, error) {
+	indent := f.Indent
+	if indent == "" {
+		indent = "    "
+	}
+	names := outputTypeNames(types)
+	typingImports := map[string]bool{}
+	usesDatetime := false
+	usesField := false
+	var classes []string
+	for _, typ := range types {
+		result := fmt.Sprintf("@dataclass\nclass %s:\n", typ.Name)
+		if len(typ.Fields) == 0 {
+			result += indent + "pass\n"
+		}
+		attributes := map[string]string{} // JSON names by attribute names
+		for _, field := range typ.Fields {
+			attribute := pythonAttributeName(field.Name)
+			if other, found := attributes[attribute]; found {
+				return "", fmt.Errorf("cannot convert %s.%s: %s and %s are both converted to the attribute %s", typ.Type.String(), field.Name, other, field.Name, attribute)
+			}
+			attributes[attribute] = field.Name
+			pythonType, err := pythonFieldType(names, typingImports, field)
+			if err != nil {
+				return "", fmt.Errorf("cannot convert %s.%s: %s", typ.Type.String(), field.Name, err.Error())
+			}
+			if pythonType == "datetime" {
+				usesDatetime = true
+			}
+			if field.Optional {
+				typingImports["Optional"] = true
+				pythonType = "Optional[" + pythonType + "]"
+			}
+			if attribute != field.Name {
+				usesField = true
+				result += fmt.Sprintf("%s%s: %s = field(metadata={\"json\": %q})\n", indent, attribute, pythonType, field.Name)
+			} else {
+				result += fmt.Sprintf("%s%s: %s\n", indent, attribute, pythonType)
+			}
+		}
+		classes = append(classes, result)
+	}
+
+	imports := "from __future__ import annotations\n\nfrom dataclasses import dataclass\n"
+	if usesField {
+		imports = "from __future__ import annotations\n\nfrom dataclasses import dataclass, field\n"
+	}
+	if usesDatetime {
+		imports += "from datetime import datetime\n"
+	}
+	if len(typingImports) > 0 {
+		var typingNames []string
+		for name := range typingImports {
+			typingNames = append(typingNames, name)
+		}
+		sort.Strings(typingNames)
+		imports += fmt.Sprintf("from typing import %s\n", strings.Join(typingNames, ", "))
+	}
+
+	return imports + "\n\n" + strings.Join(classes, "\n\n"), nil
+}
+
+// pythonAttributeName returns the attribute name of the JSON name, with the invalid characters replaced with
+// underscores, and an underscore after keywords.
+func pythonAttributeName(jsonName string) string {
+	if pythonIdentifierRegexp.MatchString(jsonName) && !pythonKeywords[jsonName] {
+		return jsonName
+	}
+	name := pythonInvalidRegexp.ReplaceAllString(jsonName, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if pythonKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// pythonFieldType returns the Python type of the field, string encoded fields and fields with a TypeScript type are
+// converted by their JSON type.
+func pythonFieldType(names map[reflect.Type]string, typingImports map[string]bool, field OutputField) (string, error) {
+	valueType, found := field.valueType()
+	if !found {
+		return pythonType(names, typingImports, field.Type)
+	}
+	switch valueType {
+	case "string":
+		return "str", nil
+	case "number":
+		return "float", nil
+	case "boolean":
+		return "bool", nil
+	}
+	typingImports["Any"] = true
+	return "Any", nil
+}
+
+// pythonType returns the Python type of the Golang type, the used `typing` names are added to typingImports.
+func pythonType(names map[reflect.Type]string, typingImports map[string]bool, typ reflect.Type) (string, error) {
+	if typ == timeType {
+		return "datetime", nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := pythonType(names, typingImports, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		typingImports["Optional"] = true
+		return "Optional[" + elem + "]", nil
+	case reflect.Slice, reflect.Array:
+		if typ == rawMessageType {
+			typingImports["Any"] = true
+			return "Any", nil
+		}
+		if isByteSlice(typ) {
+			return "str", nil
+		}
+		elem, err := pythonType(names, typingImports, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		typingImports["List"] = true
+		return "List[" + elem + "]", nil
+	case reflect.Map:
+		key, err := pythonType(names, typingImports, typ.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := pythonType(names, typingImports, typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		typingImports["Dict"] = true
+		return "Dict[" + key + ", " + value + "]", nil
+	case reflect.Struct:
+		return outputTypeName(names, typ), nil
+	case reflect.Interface:
+		typingImports["Any"] = true
+		return "Any", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.String:
+		return "str", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int", nil
+	case reflect.Float32, reflect.Float64:
+		return "float", nil
+	}
+	return "", fmt.Errorf("no Python type for %s", typ.String())
+}
@@ -0,0 +1,216 @@
+package typescriptify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+)
+
+const tsSplitConvertValuesFunc = `function convertValues(a: any, create: (source: any) => any, asMap: boolean = false): any {
+	if (!a) {
+		return a;
+	}
+	if (a.slice) {
+		return (a as any[]).map(elem => convertValues(elem, create, asMap));
+	} else if ("object" === typeof a) {
+		if (asMap) {
+			const result: any = {};
+			for (const key of Object.keys(a)) {
+				result[key] = convertValues(a[key], create);
+			}
+			return result;
+		}
+		return create(a);
+	}
+	return a;
+}`
+
+// converter holds the object literal lines used to create an entity from JSON (in the createXxx() function).
+type converter struct {
+	entityName     string
+	body           []string
+	convertsValues bool // The structs of some fields are created with convertValues()
+	instance       bool // Creates the instance of the class (with its prototype) instead of a plain object
+	dontExport     bool
+}
+
+// ConvertSplit converts the types into two modules. The first one contains only the interfaces, the second one
+// contains a `createXxx()` function for every interface (and imports the interfaces from typesModule). The custom
+// imports are in both modules.
+func (t *TypeScriptify) ConvertSplit(typesModule string) (string, string, error) {
+	createInterface := t.CreateInterface
+	t.CreateInterface = true
+	types, err := t.Convert(nil)
+	t.CreateInterface = createInterface
+	if err != nil {
+		return "", "", err
+	}
+
+	var names []string
+	functions := ""
+	convertsValues := false
+	for _, c := range t.converters {
+		names = append(names, c.entityName)
+		functions += "\n" + t.convertConverter(c)
+		convertsValues = convertsValues || c.convertsValues
+	}
+	if len(names) == 0 {
+		return types, "", nil
+	}
+
+	converters := ""
+	for _, cimport := range t.customImports { // The transformations are in the converters, too
+		converters += cimport + "\n"
+	}
+	converters += fmt.Sprintf("import { %s } from '%s';\n", strings.Join(names, ", "), typesModule)
+	if convertsValues {
+		converters += "\n" + strings.ReplaceAll(tsSplitConvertValuesFunc, "\t", t.Indent) + "\n"
+	}
+	converters += functions
+
+	return types, converters, nil
+}
+
+func (t *TypeScriptify) convertConverter(c converter) string {
+	result := fmt.Sprintf("function create%s(source: any = {}): %s {\n", c.entityName, c.entityName)
+	if !c.dontExport {
+		result = "export " + result
+	}
+	result += t.Indent + "if ('string' === typeof source) source = JSON.parse(source);\n"
+	if c.instance { // Created with the class prototype, so that it's an instance of the class (with its methods)
+		result += fmt.Sprintf("%sreturn Object.assign(Object.create(%s.prototype), {\n", t.Indent, c.entityName)
+	} else {
+		result += t.Indent + "return {\n"
+	}
+	for _, line := range c.body {
+		result += line + "\n"
+	}
+	if c.instance {
+		result += fmt.Sprintf("%s}) as %s;\n", t.Indent, c.entityName)
+	} else {
+		result += t.Indent + "};\n"
+	}
+	result += "}\n"
+	return result
+}
+
+// ConvertToFiles saves the interfaces in typesFile and the functions to create them in convertersFile.
+func (t TypeScriptify) ConvertToFiles(typesFile, convertersFile string) error {
+	typesModule := "./" + strings.TrimSuffix(path.Base(typesFile), path.Ext(typesFile))
+	types, converters, err := t.ConvertSplit(typesModule)
+	if err != nil {
+		return err
+	}
+
+	for fileName, code := range map[string]string{typesFile: types, convertersFile: converters} {
+		if len(t.BackupDir) > 0 {
+			if err := t.backup(fileName); err != nil {
+				return err
+			}
+		}
+		if err := ioutil.WriteFile(fileName, []byte(t.withLineEndings(t.fileHeader(code)+code)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertToDir saves every entity (struct, enum, union,...) in its own file in dir (i.e. `Person.ts`), with the imports
+// of the other entities used in it. Existing files are backed up, and the custom code in them is kept.
+func (t TypeScriptify) ConvertToDir(dir string) error {
+	if _, err := t.Convert(nil); err != nil { // Needed for the file names
+		return err
+	}
+	customCode := map[string]string{}
+	for entityName := range t.entityCode {
+		fileCustomCode, err := loadCustomCode(path.Join(dir, entityName+".ts"))
+		if err != nil {
+			return err
+		}
+		for name, code := range fileCustomCode {
+			customCode[name] = code
+		}
+	}
+
+	types, err := t.ConvertTypes(customCode)
+	if err != nil {
+		return err
+	}
+	var entityNames []string
+	for entityName := range types {
+		entityNames = append(entityNames, entityName)
+	}
+	sort.Strings(entityNames)
+
+	for _, entityName := range entityNames {
+		fileName := path.Join(dir, entityName+".ts")
+		if len(t.BackupDir) > 0 {
+			if err := t.backup(fileName); err != nil {
+				return err
+			}
+		}
+		code := t.fileImports(entityName, types) + types[entityName]
+		if err := ioutil.WriteFile(fileName, []byte(t.withLineEndings(t.fileHeader(code)+code)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileImports returns the imports (and the convertValues() function, if needed) of the file with the entity, saved by
+// ConvertToDir(). Every dependency is imported from its file, with its createXxx() function and XxxFields, if used.
+func (t *TypeScriptify) fileImports(entityName string, types map[string]string) string {
+	result := ""
+	for _, cimport := range t.customImports {
+		result += cimport + "\n"
+	}
+
+	imported := map[string]bool{entityName: true}
+	for _, dependency := range t.dependencies[entityName] {
+		if imported[dependency] {
+			continue
+		}
+		imported[dependency] = true
+
+		module := "./" + dependency
+		names := []string{dependency}
+		usedAsValues := t.valueDependencies[entityName][dependency]
+		if _, found := types[dependency]; found {
+			for _, name := range []string{"create" + dependency, dependency + "Fields"} {
+				if t.usedNames[entityName][name] {
+					names = append(names, name)
+					usedAsValues = true
+				}
+			}
+		} else if t.isUsedImport(dependency) {
+			module = strings.ReplaceAll(t.ImportPath, "__TYPE__", dependency)
+		} else {
+			continue
+		}
+		importKeyword := "import"
+		if t.TypeImports && !usedAsValues {
+			importKeyword = "import type"
+		}
+		result += fmt.Sprintf("%s { %s } from '%s';\n", importKeyword, strings.Join(names, ", "), module)
+	}
+	if result != "" {
+		result += "\n"
+	}
+
+	if t.usedNames[entityName]["convertValues"] {
+		result += strings.ReplaceAll(tsSplitConvertValuesFunc, "\t", t.Indent) + "\n\n"
+	}
+	return result
+}
+
+// isUsedImport checks if the entity is an imported type (see AddImportedType()) used in the converted types.
+func (t *TypeScriptify) isUsedImport(entityName string) bool {
+	for _, typ := range t.usedImports {
+		if t.entityName(typ) == entityName {
+			return true
+		}
+	}
+	return false
+}
@@ -1,36 +1,104 @@
 package typescriptify
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/tkrajina/go-reflector/reflector"
 )
 
+// Enum styles:
+const (
+	EnumStyleEnum        = "enum"        // export enum Weekday {...}
+	EnumStyleConstEnum   = "constEnum"   // export const enum Weekday {...}
+	EnumStyleConstObject = "constObject" // export const Weekday = {...} as const;
+)
+
+// Field orders:
+const (
+	FieldOrderDeclaration  = "declaration"  // As declared in the Golang struct (default)
+	FieldOrderReverse      = "reverse"      // Reversed declaration order
+	FieldOrderAlphabetical = "alphabetical" // Sorted by TypeScript property names
+)
+
+// Custom code blocks (`//[Name:]` ... `//[end]`), the code between the markers is kept when the file is regenerated:
+const (
+	CustomCodeStartPrefix = "//["
+	CustomCodeStartSuffix = ":]"
+	CustomCodeEnd         = "//[end]"
+)
+
+// Markers of the generated code in hand-maintained files (see ConvertIntoFile()):
+const (
+	GeneratedStart = "// <generated>"
+	GeneratedEnd   = "// </generated>"
+)
+
+// Values of missing nested structs (see TypeScriptify.NullValue):
+const (
+	NullValueNull      = "null"      // this.x = source["x"] ? ... : null;
+	NullValueUndefined = "undefined" // this.x = source["x"] ? ... : undefined;
+	NullValueSkip      = "skip"      // if (source["x"]) this.x = ...;
+)
+
 const (
 	tsTransformTag      = "ts_transform"
 	tsType              = "ts_type"
+	tsNameTag           = "ts_name"
+	tsReadonlyTag       = "ts_readonly"
+	tsDocTag            = "ts_doc"
+	tsTransformBackTag  = "ts_transform_back"
+	tsOptionalTag       = "ts_optional"
+	tsDefaultTag        = "ts_default"
+	tsKeyTypeTag        = "ts_key_type"
+	tsInlineTag         = "ts_inline"
+	tsFlattenTag        = "ts_flatten"
 	tsConvertValuesFunc = `convertValues(a: any, classs: any, asMap: boolean = false): any {
 	if (!a) {
 		return a;
 	}
 	if (a.slice) {
-		return (a as any[]).map(elem => this.convertValues(elem, classs));
+		return (a as any[]).map(elem => this.convertValues(elem, classs, asMap));
 	} else if ("object" === typeof a) {
 		if (asMap) {
+			const result: any = {};
 			for (const key of Object.keys(a)) {
-				a[key] = new classs(a[key]);
+				result[key] = this.convertValues(a[key], classs);
 			}
-			return a;
+			return result;
 		}
 		return new classs(a);
 	}
 	return a;
+}`
+	tsCreateFromValuesFunc = `static createFromValues(a: any, classs: any, asMap: boolean = false): any {
+	if (!a) {
+		return a;
+	}
+	if (a.slice) {
+		return (a as any[]).map(elem => this.createFromValues(elem, classs, asMap));
+	} else if ("object" === typeof a) {
+		if (asMap) {
+			const result: any = {};
+			for (const key of Object.keys(a)) {
+				result[key] = this.createFromValues(a[key], classs);
+			}
+			return result;
+		}
+		return classs.createFrom(a);
+	}
+	return a;
 }`
 )
 
@@ -44,6 +112,8 @@ type TypeOptions struct {
 type StructType struct {
 	Type         reflect.Type
 	FieldOptions map[reflect.Type]TypeOptions
+	// DiscriminatorValue is used in the root union dispatcher, if empty the type name is used
+	DiscriminatorValue string
 }
 
 func NewStruct(i interface{}) *StructType {
@@ -66,46 +136,186 @@ func (st *StructType) WithFieldOpts(i interface{}, opts TypeOptions) *StructType
 	return st
 }
 
+func (st *StructType) WithDiscriminatorValue(value string) *StructType {
+	st.DiscriminatorValue = value
+	return st
+}
+
 type EnumType struct {
 	Type reflect.Type
 }
 
+type sumType struct {
+	name          string
+	discriminator string
+	iface         reflect.Type // Fields of this (Golang) interface type are converted to the union
+	variants      []reflect.Type
+}
+
+type stringUnion struct {
+	name   string
+	values []string
+}
+
+type computedField struct {
+	declaration string
+	initializer string
+}
+
+// createFromSwitch is code run when creating an entity, depending on the value of the discriminator field.
+type createFromSwitch struct {
+	discriminator string
+	cases         map[string]string // TypeScript literal value (or "default") to code
+}
+
 type enumElement struct {
 	value interface{}
 	name  string
 }
 
+// Logger logs a message (i.e. log.Printf).
+type Logger func(format string, args ...interface{})
+
+// FieldNamer returns the TypeScript property name for a field (with the JSON field name jsonName).
+type FieldNamer func(jsonName string, field reflect.StructField) string
+
 type TypeScriptify struct {
-	Prefix            string
-	Suffix            string
-	Indent            string
-	CreateFromMethod  bool
-	CreateConstructor bool
-	BackupDir         string // If empty no backup
-	DontExport        bool
-	CreateInterface   bool
-	customImports     []string
+	Prefix                    string
+	Suffix                    string
+	StripSuffix               string // Suffix removed from Golang type names (i.e. `DTO`)
+	Indent                    string
+	CreateFromMethod          bool
+	FreezeCreateFrom          bool // createFrom returns a frozen (Readonly) object
+	LiteralCreateFrom         bool // createFrom returns an object literal (instead of a class instance)
+	MemoizeCreateFrom         bool // createFrom caches the created objects by source object (in a WeakMap), also for nested objects
+	ExternalCreateFrom        bool // createFrom calls a (standalone) createXxx() function which creates the class instance
+	ImmutableSource           bool // createFrom and the constructor take a Readonly<> source
+	CreateConstructor         bool
+	BackupDir                 string // If empty (default) no backup
+	FixedBackupName           bool   // The backup is `<file>.backup` (overwritten every time) instead of a timestamped file
+	DontExport                bool
+	CreateInterface           bool
+	ReadonlyTypeAlias         bool                // Create `type Foo = Readonly<{...}>` aliases (instead of classes or interfaces)
+	DeclarationOnly           bool                // Create declarations (without method bodies) for .d.ts files
+	RootUnion                 string              // If not empty, a union type with this name is created from all added root types
+	RootUnionDiscriminator    string              // If not empty, a createFromAny() dispatching on this field is created for the root union
+	TypeDiscriminator         string              // If not empty, every type has a readonly field with this name set to the type name (`readonly _type: "Foo" = "Foo";`)
+	NamedScalars              bool                // Named bool/number/string types (i.e. `type Flag bool`) are converted to type aliases
+	BrandedScalars            bool                // Named scalar types are converted to branded aliases (`number & { readonly __brand: 'UserID' }`)
+	QuoteAllPropertyNames     bool                // Quote all property names (`"name": string;`)
+	ExplicitUndefined         bool                // Optional fields are explicitly set to undefined when missing in the source
+	TrailingCommas            bool                // Add trailing commas in inline object types
+	InstantiateMissingStructs bool                // Missing (non-pointer) struct fields are initialized with an empty instance
+	WarnEmbeddedInterfaces    bool                // Log (with Logf) a warning for (ignored) embedded interfaces
+	Logf                      Logger              // If set, logs the warnings and the skipped fields (and why they are skipped)
+	ImplementInterfaces       bool                // Classes implement (and interfaces extend) TS interfaces created for embedded named interfaces
+	TimeAsDate                bool                // Deprecated: use TimeType ("Date"), used only if TimeType isn't set (or "string")
+	CreateFromPartial         bool                // Create a fromPartial() method, which sets the missing fields to default (zero or empty) values
+	ReadonlyFields            bool                // All the fields are readonly
+	PrivateFields             bool                // Class fields are ECMAScript private (`#name`) with public accessors, toJSON() is always created
+	ReadonlyContainers        bool                // Slices are ReadonlyArray<T> and maps Readonly<{[key: K]: V}>
+	ArrayTuples               bool                // Fixed size arrays are tuples (i.e. `[number, number, number]` instead of `number[]`)
+	NullableElements          bool                // Elements of slices of pointers (i.e. `[]*Foo`) can be null: `(Foo | null)[]`
+	NullablePointers          bool                // All pointer fields (not only structs, slices and arrays) are nullable: `count?: number | null`
+	NullishOptionals          bool                // Nullable omitempty fields (i.e. `*Foo` with omitempty) are `foo?: Foo | null | undefined`
+	ByteArraysAsStrings       bool                // Fixed size byte arrays (i.e. `[16]byte` UUIDs) are strings, for types which marshal them as hex or base64
+	StrictNames               bool                // Fail if a type without a name (i.e. an anonymous struct) would be converted
+	AnonymousStructNames      bool                // Anonymous structs are named by their field path (i.e. `OrderMeta` for the Order.Meta field)
+	ErrorOnEmpty              bool                // Fail if a struct has no fields (i.e. only unexported fields or fields without JSON names)
+	Strict                    bool                // Fail on fields which can't be represented in TypeScript (chan, func, complex, unsafe.Pointer) instead of skipping them
+	SeeComments               bool                // Add `/** @see Foo */` comments to fields referencing other generated types
+	SourceComments            bool                // Add `/** @source github.com/org/pkg.Foo */` comments with the Golang type to the generated types
+	TagComments               bool                // Add the Go struct tag as a comment above every field
+	SkippedFieldsComment      bool                // Add a `// skipped: Password, internalFlag` comment listing the skipped fields at the bottom of every type
+	EnumStyle                 string              // EnumStyleEnum (default), EnumStyleConstEnum or EnumStyleConstObject
+	EnumNames                 bool                // Create a reverse lookup map of numeric enums (`export const WeekdayNames = {0: "Sunday", ...}`)
+	HashComment               bool                // Add a hash of the generated code as a comment (to detect if the code needs to be regenerated)
+	LineEnding                string              // Line ending of the written files ("\n" by default, "\r\n" for Windows)
+	Header                    string              // Comment at the start of the written files (written as it is, no header if empty)
+	BlankLines                bool                // Separate the types (and groups of fields promoted from embedded structs) with blank lines
+	Namespace                 string              // If set, the code is wrapped in `export namespace Namespace {...}`
+	DefaultExportNamespace    bool                // The Namespace is also the default export (`import Models from './models'`)
+	NumberCoercion            string              // If set (i.e. "Number"), numbers (also in slices and maps) are reconstructed with this function
+	OptionalChaining          bool                // Source fields are accessed with optional chaining (`source?.["x"]`), so that null sources don't throw
+	FalseForOmittedBools      bool                // Missing omitempty bool fields are false (instead of undefined) in the constructor
+	FieldOrder                string              // FieldOrderDeclaration (default), FieldOrderReverse or FieldOrderAlphabetical
+	SortFields                bool                // Same as FieldOrderAlphabetical
+	NullValue                 string              // Value of missing nested structs: NullValueNull, NullValueUndefined, NullValueSkip or empty (the source value)
+	SourceType                string              // Type of the createFrom() and constructor parameter ("any" by default, i.e. "Record<string, any>" or "Partial<__TYPE__>")
+	CreateFromMethodName      string              // Name of the createFrom() method ("createFrom" by default, i.e. "fromJSON")
+	ClassKeyword              string              // Keyword of the classes ("class" by default, i.e. "abstract class")
+	Inheritance               bool                // Embedded structs are extended (instead of flattening their fields)
+	TimeType                  string              // TypeScript type of time.Time fields ("string" by default, "Date" creates dates in the constructor)
+	RawMessageType            string              // TypeScript type of json.RawMessage fields ("any" by default, or "unknown")
+	LooseNullability          bool                // All fields are optional (`T | undefined`), missing non-pointer fields get zero values in the constructor
+	InterfaceType             string              // TypeScript type of interface{} fields, elements and values ("any" by default, "unknown" requires type checks before use)
+	OmitEmptyCustomBlocks     bool                // Custom code blocks (`//[Foo:]...//[end]`) without code (or only with whitespace) are omitted
+	CreateToJSON              bool                // Create a toJSON() method which returns the object with the original JSON field names
+	CreateClone               bool                // Create a clone() method which deep copies the object
+	CreateToFormData          bool                // Create a toFormData() method for multipart (file upload) forms
+	CreateSetter              bool                // Create a type safe set<K extends keyof Foo>(key: K, value: Foo[K]) method
+	CreateFieldNames          bool                // Create a const array with the property names of every type (`FooFields`)
+	CreateComparators         bool                // Create a `compareFooBy(key)` function returning a comparator (for sorting) by a number, string or boolean property
+	GeneratePatchTypes        bool                // Create a `type FooPatch = Partial<Foo>` for every added struct (i.e. for PATCH payloads)
+	ResponseEnvelope          string              // If set, create a `type FooResponse = ApiResponse<Foo>` for every added struct (`__TYPE__` is replaced with the name)
+	ClassValidator            bool                // Add class-validator decorators (`@IsString()`, `@ValidateNested()`, ...) to class fields
+	ImportPath                string              // Module path of imported types, `__TYPE__` is replaced with the type name
+	TypeImports               bool                // Imported types used only as types are imported with `import type` (for `isolatedModules`)
+	FieldNameTransform        func(string) string // Transforms JSON field names to TypeScript property names
+	EnumMemberTransform       func(string) string // Transforms enum member names
+	FieldNameFunc             FieldNamer          // Returns TypeScript property names (overrides FieldNameTransform)
+	SuffixCollidingNames      bool                // Properties colliding after the name transformation get a numeric suffix (`id2`) instead of failing
+	customImports             []string
+	customCode                map[string]string
+	computedFields            map[string][]computedField
+	methodSignatures          map[string][]string
+	createFromSwitches        map[string]createFromSwitch
+	importedTypes             map[reflect.Type]bool
 
 	structTypes []StructType
 	enumTypes   []EnumType
+	sumTypes    []sumType
+	unions      []stringUnion
 	enums       map[reflect.Type][]enumElement
 	kinds       map[reflect.Kind]string
 
 	fieldTypeOptions map[reflect.Type]TypeOptions
+	containerTypes   map[reflect.Type]reflect.Type
 
 	// throwaway, used when converting
-	alreadyConverted map[reflect.Type]bool
+	alreadyConverted  map[reflect.Type]bool
+	manifest          []ManifestType
+	entityTypes       map[string]reflect.Type
+	anonymousNames    map[reflect.Type]string
+	usedImports       []reflect.Type
+	converters        []converter
+	entityCode        map[string]string
+	path              []string // Type and field names of the field currently converted
+	usesConvertValues bool     // The convertValues() function is used by the createXxx() functions
+	usedDecorators    map[string]bool
+	// Names of the entities used in every entity (can include unconverted types):
+	dependencies map[string][]string
+	// Names of the entities used as values (`new Foo()`, `extends Foo`, ...) and not only as types in every entity:
+	valueDependencies map[string]map[string]bool
+	// Names of the functions and constants (`createFoo()`, `FooFields`, `convertValues()`) used in every entity:
+	usedNames map[string]map[string]bool
 }
 
 func New() *TypeScriptify {
 	result := new(TypeScriptify)
-	result.Indent = "\t"
-	result.BackupDir = "."
+	result.Indent = "    " // Four spaces
+	result.ImportPath = "./__TYPE__"
+	result.TimeType = "string"
+	result.RawMessageType = "any"
+	result.CreateFromMethodName = "createFrom"
+	result.ClassKeyword = "class"
+	result.SourceType = "any"
+	result.LineEnding = "\n"
+	result.Header = "/* Do not change, this code is generated from Golang structs */"
 
 	kinds := make(map[reflect.Kind]string)
 
 	kinds[reflect.Bool] = "boolean"
-	kinds[reflect.Interface] = "any"
 
 	kinds[reflect.Int] = "number"
 	kinds[reflect.Int8] = "number"
@@ -124,7 +334,6 @@ func New() *TypeScriptify {
 
 	result.kinds = kinds
 
-	result.Indent = "    "
 	result.CreateFromMethod = true
 	result.CreateConstructor = true
 
@@ -134,8 +343,52 @@ func New() *TypeScriptify {
 	return result
 }
 
+type embeddedField struct {
+	field    reflect.StructField
+	depth    int
+	position int
+	root     int // Index of the field (or embedded struct containing it) in the root struct
+}
+
+// deepFields returns the struct fields, including the ones promoted from embedded structs. As in encoding/json, when
+// more fields have the same JSON name, the shallowest one is used (or the tagged one if more are at the same depth),
+// and if that doesn't resolve the conflict, all the conflicting fields are ignored.
 func deepFields(typeOf reflect.Type) []reflect.StructField {
+	return deepFieldsExcept(typeOf, nil)
+}
+
+// deepFieldsExcept is deepFields without the fields promoted from the excluded (root) embedded structs.
+func deepFieldsExcept(typeOf reflect.Type, excluded map[int]bool) []reflect.StructField {
+	embeddedFields := collectDeepFields(typeOf, 0)
+
+	// The ts_inline maps aren't JSON fields (usually `json:"-"`), so they don't conflict with other fields:
+	conflictName := func(f embeddedField) string {
+		if f.field.Tag.Get(tsInlineTag) == "true" {
+			return fmt.Sprintf("%s:%d", tsInlineTag, f.position)
+		}
+		return jsonName(f.field)
+	}
+	byName := map[string][]embeddedField{}
+	for n := range embeddedFields {
+		embeddedFields[n].position = n
+		name := conflictName(embeddedFields[n])
+		byName[name] = append(byName[name], embeddedFields[n])
+	}
+
 	fields := make([]reflect.StructField, 0)
+	for _, f := range embeddedFields {
+		if excluded[f.root] {
+			continue
+		}
+		if dominant, found := dominantField(byName[conflictName(f)]); found && dominant.position == f.position {
+			fields = append(fields, f.field)
+		}
+	}
+	return fields
+}
+
+func collectDeepFields(typeOf reflect.Type, depth int) []embeddedField {
+	fields := make([]embeddedField, 0)
 
 	if typeOf.Kind() == reflect.Ptr {
 		typeOf = typeOf.Elem()
@@ -149,24 +402,171 @@ func deepFields(typeOf reflect.Type) []reflect.StructField {
 		f := typeOf.Field(i)
 
 		kind := f.Type.Kind()
-		if f.Anonymous && kind == reflect.Struct {
+		// Nested structs with ts_flatten are flattened as embedded structs:
+		embedded := f.Anonymous || f.Tag.Get(tsFlattenTag) == "true"
+		if f.Anonymous && f.Tag.Get("json") == "-" { // Ignored with all the embedded fields
+			continue
+		} else if embedded && kind == reflect.Struct {
 			//fmt.Println(v.Interface())
-			fields = append(fields, deepFields(f.Type)...)
-		} else if f.Anonymous && kind == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+			fields = append(fields, withRoot(collectDeepFields(f.Type, depth+1), i)...)
+		} else if embedded && kind == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
 			//fmt.Println(v.Interface())
-			fields = append(fields, deepFields(f.Type.Elem())...)
+			fields = append(fields, withRoot(collectDeepFields(f.Type.Elem(), depth+1), i)...)
+		} else if isUntaggedEmbeddedInterface(f) {
+			// No fields to flatten
 		} else {
-			fields = append(fields, f)
+			fields = append(fields, embeddedField{field: f, depth: depth, root: i})
+		}
+	}
+
+	return fields
+}
+
+// withRoot sets the root of the fields promoted from the embedded struct, and prepends it to their indexes (so that
+// the field index is the full index sequence, as in reflect.Type.FieldByIndex).
+func withRoot(fields []embeddedField, root int) []embeddedField {
+	for n := range fields {
+		fields[n].root = root
+		fields[n].field.Index = append([]int{root}, fields[n].field.Index...)
+	}
+	return fields
+}
+
+// promotedFrom returns the name of the embedded struct from which the field (returned by deepFields()) is promoted,
+// empty if the field is declared in the struct.
+func promotedFrom(typeOf reflect.Type, field reflect.StructField) string {
+	if len(field.Index) < 2 {
+		return ""
+	}
+	embedded := typeOf.Field(field.Index[0]).Type
+	if embedded.Kind() == reflect.Ptr {
+		embedded = embedded.Elem()
+	}
+	return typeName(embedded)
+}
+
+// embeddedStructs returns the indexes (and types) of the structs embedded in the struct.
+func embeddedStructs(typeOf reflect.Type) ([]int, []reflect.Type) {
+	var indexes []int
+	var types []reflect.Type
+	for i := 0; i < typeOf.NumField(); i++ {
+		f := typeOf.Field(i)
+		typ := f.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if f.Anonymous && typ.Kind() == reflect.Struct && f.Tag.Get("json") != "-" {
+			indexes = append(indexes, i)
+			types = append(types, typ)
+		}
+	}
+	return indexes, types
+}
+
+func isUntaggedEmbeddedInterface(f reflect.StructField) bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Interface && f.Tag.Get("json") == ""
+}
+
+// embeddedInterfaces returns all untagged embedded interfaces (including the ones in embedded structs).
+func embeddedInterfaces(typeOf reflect.Type) []reflect.StructField {
+	var result []reflect.StructField
+	if typeOf.Kind() == reflect.Ptr {
+		typeOf = typeOf.Elem()
+	}
+	if typeOf.Kind() != reflect.Struct {
+		return result
+	}
+	for i := 0; i < typeOf.NumField(); i++ {
+		f := typeOf.Field(i)
+		if isUntaggedEmbeddedInterface(f) {
+			result = append(result, f)
+		} else if f.Anonymous && f.Tag.Get("json") != "-" {
+			result = append(result, embeddedInterfaces(f.Type)...)
 		}
 	}
+	return result
+}
 
+// orderFields orders the fields as set in FieldOrder (SortFields is the same as FieldOrderAlphabetical).
+func (t *TypeScriptify) orderFields(fields []reflect.StructField) []reflect.StructField {
+	order := t.FieldOrder
+	if t.SortFields {
+		order = FieldOrderAlphabetical
+	}
+	switch order {
+	case FieldOrderReverse:
+		for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+			fields[i], fields[j] = fields[j], fields[i]
+		}
+	case FieldOrderAlphabetical:
+		sort.SliceStable(fields, func(i, j int) bool {
+			return t.propertyName(fields[i]) < t.propertyName(fields[j])
+		})
+	}
 	return fields
 }
 
+// propertyName returns the TypeScript property name of the field. The ts_name tag has precedence over FieldNameFunc,
+// which has precedence over FieldNameTransform (of the JSON field name).
+func (t *TypeScriptify) propertyName(field reflect.StructField) string {
+	if tsName := field.Tag.Get(tsNameTag); tsName != "" {
+		return tsName
+	}
+	jsonFieldName := strings.TrimSuffix(t.getJSONFieldName(field, false), "?")
+	if t.FieldNameFunc != nil {
+		return t.FieldNameFunc(jsonFieldName, field)
+	}
+	if t.FieldNameTransform != nil {
+		return t.FieldNameTransform(jsonFieldName)
+	}
+	return jsonFieldName
+}
+
+// jsonName returns the name used by encoding/json, the field name if not tagged.
+func jsonName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func dominantField(fields []embeddedField) (embeddedField, bool) {
+	var shallowest []embeddedField
+	for _, f := range fields {
+		if len(shallowest) == 0 || f.depth < shallowest[0].depth {
+			shallowest = []embeddedField{f}
+		} else if f.depth == shallowest[0].depth {
+			shallowest = append(shallowest, f)
+		}
+	}
+
+	var tagged []embeddedField
+	for _, f := range shallowest {
+		if strings.Split(f.field.Tag.Get("json"), ",")[0] != "" {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) > 0 {
+		shallowest = tagged
+	}
+
+	if len(shallowest) != 1 {
+		return embeddedField{}, false
+	}
+	return shallowest[0], true
+}
+
 func (ts TypeScriptify) logf(depth int, s string, args ...interface{}) {
 	fmt.Printf(strings.Repeat("   ", depth)+s+"\n", args...)
 }
 
+// logSkippedField logs (with Logf, if set) why the field isn't converted.
+func (t TypeScriptify) logSkippedField(typeOf reflect.Type, field reflect.StructField, reason string) {
+	if t.Logf != nil {
+		t.Logf("skipped field %s.%s: %s", typeOf.Name(), field.Name, reason)
+	}
+}
+
 // ManageType can define custom options for fields of a specified type.
 //
 // This can be used instead of setting ts_type and ts_transform for all fields of a certain type.
@@ -185,200 +585,1066 @@ func (t *TypeScriptify) ManageType(fld interface{}, opts TypeOptions) *TypeScrip
 	return t
 }
 
+// ManageContainerType declares the JSON shape of a custom container type (i.e. a struct with a sync.Map which is
+// marshalled as an object), which can't be introspected. The shape is a type (or a value of it) marshalled the same
+// way, so `ManageContainerType(Registry{}, map[string]Foo{})` converts Registry fields as `{[key: string]: Foo}` (and
+// reconstructs the Foo values), also in slices and maps of Registry.
+func (t *TypeScriptify) ManageContainerType(container interface{}, shape interface{}) *TypeScriptify {
+	typeOf := func(v interface{}) reflect.Type {
+		if typ, is := v.(reflect.Type); is {
+			return typ
+		}
+		return reflect.TypeOf(v)
+	}
+	if t.containerTypes == nil {
+		t.containerTypes = map[reflect.Type]reflect.Type{}
+	}
+	t.containerTypes[typeOf(container)] = typeOf(shape)
+	return t
+}
+
+// AddTypeMapping changes the TypeScript type used for all fields of a kind (i.e. `reflect.Int64` as `string`).
+//
+// The mapping applies to all the types converted after it's set, including slice and map elements.
+func (t *TypeScriptify) AddTypeMapping(kind reflect.Kind, tsType string) *TypeScriptify {
+	t.kinds[kind] = tsType
+	return t
+}
+
 func (t *TypeScriptify) WithCreateFromMethod(b bool) *TypeScriptify {
 	t.CreateFromMethod = b
 	return t
 }
 
+func (t *TypeScriptify) WithFreezeCreateFrom(b bool) *TypeScriptify {
+	t.FreezeCreateFrom = b
+	return t
+}
+
 func (t *TypeScriptify) WithInterface(b bool) *TypeScriptify {
 	t.CreateInterface = b
 	return t
 }
 
-func (t *TypeScriptify) WithConstructor(b bool) *TypeScriptify {
-	t.CreateConstructor = b
+func (t *TypeScriptify) WithReadonlyTypeAlias(b bool) *TypeScriptify {
+	t.ReadonlyTypeAlias = b
 	return t
 }
 
-func (t *TypeScriptify) WithIndent(i string) *TypeScriptify {
-	t.Indent = i
+func (t *TypeScriptify) WithDeclarationOnly(b bool) *TypeScriptify {
+	t.DeclarationOnly = b
 	return t
 }
 
-func (t *TypeScriptify) WithBackupDir(b string) *TypeScriptify {
-	t.BackupDir = b
+func (t *TypeScriptify) WithNamedScalars(b bool) *TypeScriptify {
+	t.NamedScalars = b
 	return t
 }
 
-func (t *TypeScriptify) WithPrefix(p string) *TypeScriptify {
-	t.Prefix = p
+// WithBrandedScalars converts named scalar types to branded type aliases (and implies WithNamedScalars).
+func (t *TypeScriptify) WithBrandedScalars(b bool) *TypeScriptify {
+	t.BrandedScalars = b
 	return t
 }
 
-func (t *TypeScriptify) WithSuffix(s string) *TypeScriptify {
-	t.Suffix = s
+func (t *TypeScriptify) WithRootUnion(name string) *TypeScriptify {
+	t.RootUnion = name
 	return t
 }
 
-func (t *TypeScriptify) Add(obj interface{}) *TypeScriptify {
-	switch ty := obj.(type) {
-	case StructType:
-		t.structTypes = append(t.structTypes, ty)
-	case *StructType:
-		t.structTypes = append(t.structTypes, *ty)
-	case reflect.Type:
-		t.AddType(ty)
-	default:
-		t.AddType(reflect.TypeOf(obj))
-	}
+func (t *TypeScriptify) WithQuoteAllPropertyNames(b bool) *TypeScriptify {
+	t.QuoteAllPropertyNames = b
 	return t
 }
 
-func (t *TypeScriptify) AddType(typeOf reflect.Type) *TypeScriptify {
-	t.structTypes = append(t.structTypes, StructType{Type: typeOf})
+func (t *TypeScriptify) WithRootUnionDiscriminator(fieldName string) *TypeScriptify {
+	t.RootUnionDiscriminator = fieldName
 	return t
 }
 
-func (t *typeScriptClassBuilder) AddMapField(fieldName string, field reflect.StructField) {
-	keyType := field.Type.Key()
-	valueType := field.Type.Elem()
-	valueTypeName := valueType.Name()
-	if name, ok := t.types[valueType.Kind()]; ok {
-		valueTypeName = name
-	}
-	if valueType.Kind() == reflect.Array || valueType.Kind() == reflect.Slice {
-		valueTypeName = valueType.Elem().Name() + "[]"
-	}
-	if valueType.Kind() == reflect.Ptr {
-		valueTypeName = valueType.Elem().Name()
-	}
-	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
+// WithTypeDiscriminator adds a readonly field with the (TypeScript) type name to every type (i.e.
+// `readonly _type: "Foo" = "Foo";` with "_type"), so that objects in heterogeneous arrays can be narrowed at runtime.
+// Types which already have a field with the name are left as they are. Types extending other types can't redeclare
+// the field, so converting them is an error.
+func (t *TypeScriptify) WithTypeDiscriminator(fieldName string) *TypeScriptify {
+	t.TypeDiscriminator = fieldName
+	return t
+}
 
-	t.fields = append(t.fields, fmt.Sprintf("%s%s: {[key: %s]: %s};", t.indent, fieldName, t.prefix+keyType.Name()+t.suffix, valueTypeName))
-	if valueType.Kind() == reflect.Struct {
-		t.constructorBody = append(t.constructorBody, fmt.Sprintf("%s%sthis.%s = this.convertValues(source[\"%s\"], %s, true);", t.indent, t.indent, strippedFieldName, strippedFieldName, t.prefix+valueTypeName+t.suffix))
-	} else {
-		t.constructorBody = append(t.constructorBody, fmt.Sprintf("%s%sthis.%s = source[\"%s\"];", t.indent, t.indent, strippedFieldName, strippedFieldName))
-	}
+func (t *TypeScriptify) WithExplicitUndefined(b bool) *TypeScriptify {
+	t.ExplicitUndefined = b
+	return t
 }
 
-func (t *TypeScriptify) AddEnum(values interface{}) *TypeScriptify {
-	if t.enums == nil {
-		t.enums = map[reflect.Type][]enumElement{}
-	}
-	items := reflect.ValueOf(values)
-	if items.Kind() != reflect.Slice {
-		panic(fmt.Sprintf("Values for %T isn't a slice", values))
-	}
+func (t *TypeScriptify) WithTrailingCommas(b bool) *TypeScriptify {
+	t.TrailingCommas = b
+	return t
+}
 
-	var elements []enumElement
-	for i := 0; i < items.Len(); i++ {
-		item := items.Index(i)
+func (t *TypeScriptify) WithInstantiateMissingStructs(b bool) *TypeScriptify {
+	t.InstantiateMissingStructs = b
+	return t
+}
 
-		var el enumElement
-		if item.Kind() == reflect.Struct {
-			r := reflector.New(item.Interface())
-			val, err := r.Field("Value").Get()
-			if err != nil {
-				panic(fmt.Sprint("missing Type field in ", item.Type().String()))
-			}
-			name, err := r.Field("TSName").Get()
-			if err != nil {
-				panic(fmt.Sprint("missing TSName field in ", item.Type().String()))
-			}
-			el.value = val
-			el.name = name.(string)
-		} else {
-			el.value = item.Interface()
-			if tsNamer, is := item.Interface().(TSNamer); is {
-				el.name = tsNamer.TSName()
-			} else {
-				panic(fmt.Sprint(item.Type().String(), " has no TSName method"))
-			}
-		}
+func (t *TypeScriptify) WithWarnEmbeddedInterfaces(b bool) *TypeScriptify {
+	t.WarnEmbeddedInterfaces = b
+	return t
+}
 
-		elements = append(elements, el)
-	}
-	ty := reflect.TypeOf(elements[0].value)
-	t.enums[ty] = elements
-	t.enumTypes = append(t.enumTypes, EnumType{Type: ty})
+func (t *TypeScriptify) WithLogf(f Logger) *TypeScriptify {
+	t.Logf = f
+	return t
+}
 
+func (t *TypeScriptify) WithImplementInterfaces(b bool) *TypeScriptify {
+	t.ImplementInterfaces = b
 	return t
 }
 
-// AddEnumValues is deprecated, use `AddEnum()`
-func (t *TypeScriptify) AddEnumValues(typeOf reflect.Type, values interface{}) *TypeScriptify {
-	t.AddEnum(values)
+func (t *TypeScriptify) WithLiteralCreateFrom(b bool) *TypeScriptify {
+	t.LiteralCreateFrom = b
 	return t
 }
 
-func (t *TypeScriptify) Convert(customCode map[string]string) (string, error) {
-	t.alreadyConverted = make(map[reflect.Type]bool)
-	depth := 0
+// WithMemoizeCreateFrom makes createFrom return the same object for the same source object, so that objects shared in
+// large graphs are created only once. Only for createFrom methods creating class instances, converting is an error
+// with LiteralCreateFrom, ExternalCreateFrom or FreezeCreateFrom.
+func (t *TypeScriptify) WithMemoizeCreateFrom(b bool) *TypeScriptify {
+	t.MemoizeCreateFrom = b
+	return t
+}
 
-	result := ""
-	if len(t.customImports) > 0 {
-		// Put the custom imports, i.e.: `import Decimal from 'decimal.js'`
-		for _, cimport := range t.customImports {
-			result += cimport + "\n"
-		}
-	}
+// WithImmutableSource makes the source parameter of createFrom() and the constructor Readonly<>.
+func (t *TypeScriptify) WithImmutableSource(b bool) *TypeScriptify {
+	t.ImmutableSource = b
+	return t
+}
 
-	for _, enumTyp := range t.enumTypes {
-		elements := t.enums[enumTyp.Type]
-		typeScriptCode, err := t.convertEnum(depth, enumTyp.Type, elements)
-		if err != nil {
-			return "", err
-		}
-		result += "\n" + strings.Trim(typeScriptCode, " "+t.Indent+"\r\n")
-	}
+func (t *TypeScriptify) WithExternalCreateFrom(b bool) *TypeScriptify {
+	t.ExternalCreateFrom = b
+	return t
+}
 
-	for _, strctTyp := range t.structTypes {
-		typeScriptCode, err := t.convertType(depth, strctTyp.Type, customCode)
-		if err != nil {
-			return "", err
-		}
-		result += "\n" + strings.Trim(typeScriptCode, " "+t.Indent+"\r\n")
+// WithTimeAsDate is deprecated, use `WithTimeType("Date")`.
+func (t *TypeScriptify) WithTimeAsDate(b bool) *TypeScriptify {
+	if b {
+		t.TimeType = "Date"
+	} else if t.TimeType == "Date" {
+		t.TimeType = "string"
 	}
-	return result, nil
+	return t
 }
 
-func loadCustomCode(fileName string) (map[string]string, error) {
-	result := make(map[string]string)
-	f, err := os.Open(fileName)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return result, nil
-		}
-		return result, err
-	}
-	defer f.Close()
+func (t *TypeScriptify) WithTimeType(s string) *TypeScriptify {
+	t.TimeType = s
+	return t
+}
 
-	bytes, err := ioutil.ReadAll(f)
-	if err != nil {
-		return result, err
-	}
+func (t *TypeScriptify) WithRawMessageType(s string) *TypeScriptify {
+	t.RawMessageType = s
+	return t
+}
 
-	var currentName string
-	var currentValue string
-	lines := strings.Split(string(bytes), "\n")
-	for _, line := range lines {
+// WithInterfaceType changes the TypeScript type of interface{} fields (also in slices and maps). With "unknown" the
+// values can't be used without type narrowing, which is safer than "any" but more verbose for the users of the types.
+func (t *TypeScriptify) WithInterfaceType(s string) *TypeScriptify {
+	t.InterfaceType = s
+	return t
+}
+
+// WithOmitEmptyCustomBlocks omits the custom code blocks which contain only whitespace (i.e. set with SetCustomCode),
+// instead of writing them with the blank lines.
+func (t *TypeScriptify) WithOmitEmptyCustomBlocks(b bool) *TypeScriptify {
+	t.OmitEmptyCustomBlocks = b
+	return t
+}
+
+// WithLooseNullability makes all the fields optional, for clients consuming partial data without strict null checks.
+// Missing scalars, slices and maps are initialized with zero values (with `??`), unless they have a ts_default.
+func (t *TypeScriptify) WithLooseNullability(b bool) *TypeScriptify {
+	t.LooseNullability = b
+	return t
+}
+
+func (t *TypeScriptify) WithStripSuffix(s string) *TypeScriptify {
+	t.StripSuffix = s
+	return t
+}
+
+func (t *TypeScriptify) WithImportPath(p string) *TypeScriptify {
+	t.ImportPath = p
+	return t
+}
+
+func (t *TypeScriptify) WithTypeImports(b bool) *TypeScriptify {
+	t.TypeImports = b
+	return t
+}
+
+func (t *TypeScriptify) WithToJSON(b bool) *TypeScriptify {
+	t.CreateToJSON = b
+	return t
+}
+
+// WithToFormData creates a toFormData() method, which appends all the (non-null) fields to a FormData. Scalars are
+// appended as strings, Blobs (and Files) as they are, and nested objects and arrays JSON-stringified.
+func (t *TypeScriptify) WithToFormData(b bool) *TypeScriptify {
+	t.CreateToFormData = b
+	return t
+}
+
+func (t *TypeScriptify) WithClone(b bool) *TypeScriptify {
+	t.CreateClone = b
+	return t
+}
+
+func (t *TypeScriptify) WithSetter(b bool) *TypeScriptify {
+	t.CreateSetter = b
+	return t
+}
+
+func (t *TypeScriptify) WithFieldNames(b bool) *TypeScriptify {
+	t.CreateFieldNames = b
+	return t
+}
+
+// WithPrivateFields makes the class fields hard-private (`#name`), for runtime encapsulation. They are accessed with
+// (public) getters and setters, and serialized with toJSON(). Can't be used with LiteralCreateFrom (object literals
+// can't have private fields), and fromPartial() can't set readonly private fields.
+func (t *TypeScriptify) WithPrivateFields(b bool) *TypeScriptify {
+	t.PrivateFields = b
+	return t
+}
+
+func (t *TypeScriptify) WithComparators(b bool) *TypeScriptify {
+	t.CreateComparators = b
+	return t
+}
+
+func (t *TypeScriptify) WithPatchTypes(b bool) *TypeScriptify {
+	t.GeneratePatchTypes = b
+	return t
+}
+
+// WithResponseEnvelope sets the generic type wrapping the API responses, i.e. with "ApiResponse" every added struct
+// gets a `type FooResponse = ApiResponse<Foo>`. The envelope type must be imported or defined in custom code.
+func (t *TypeScriptify) WithResponseEnvelope(s string) *TypeScriptify {
+	t.ResponseEnvelope = s
+	return t
+}
+
+func (t *TypeScriptify) WithClassValidator(b bool) *TypeScriptify {
+	t.ClassValidator = b
+	return t
+}
+
+// WithFieldNameTransform transforms the JSON field names to TypeScript property names (unless set with ts_name or
+// FieldNameFunc).
+func (t *TypeScriptify) WithFieldNameTransform(f func(string) string) *TypeScriptify {
+	t.FieldNameTransform = f
+	return t
+}
+
+// WithFieldNameFunc sets the function returning the TypeScript property names (unless set with ts_name), it has
+// precedence over FieldNameTransform.
+func (t *TypeScriptify) WithFieldNameFunc(f FieldNamer) *TypeScriptify {
+	t.FieldNameFunc = f
+	return t
+}
+
+// WithSuffixCollidingNames resolves properties which collide after the name transformation (i.e. "user_id" and
+// "userId" are both "userId") by adding a numeric suffix to the later fields ("userId2"). Without it, the conversion
+// fails.
+func (t *TypeScriptify) WithSuffixCollidingNames(b bool) *TypeScriptify {
+	t.SuffixCollidingNames = b
+	return t
+}
+
+func (t *TypeScriptify) WithEnumMemberTransform(f func(string) string) *TypeScriptify {
+	t.EnumMemberTransform = f
+	return t
+}
+
+func (t *TypeScriptify) WithInheritance(b bool) *TypeScriptify {
+	t.Inheritance = b
+	return t
+}
+
+func (t *TypeScriptify) WithSourceType(s string) *TypeScriptify {
+	t.SourceType = s
+	return t
+}
+
+func (t *TypeScriptify) WithCreateFromMethodName(name string) *TypeScriptify {
+	t.CreateFromMethodName = name
+	return t
+}
+
+func (t *TypeScriptify) WithClassKeyword(keyword string) *TypeScriptify {
+	t.ClassKeyword = keyword
+	return t
+}
+
+func (t *TypeScriptify) WithTagComments(b bool) *TypeScriptify {
+	t.TagComments = b
+	return t
+}
+
+// WithSkippedFieldsComment adds a comment with the (Golang) names of the fields which aren't converted (ignored with
+// `json:"-"`, without a JSON name or unsupported) at the bottom of every type.
+func (t *TypeScriptify) WithSkippedFieldsComment(b bool) *TypeScriptify {
+	t.SkippedFieldsComment = b
+	return t
+}
+
+func (t *TypeScriptify) WithEnumStyle(s string) *TypeScriptify {
+	t.EnumStyle = s
+	return t
+}
+
+func (t *TypeScriptify) WithEnumNames(b bool) *TypeScriptify {
+	t.EnumNames = b
+	return t
+}
+
+func (t *TypeScriptify) WithHashComment(b bool) *TypeScriptify {
+	t.HashComment = b
+	return t
+}
+
+func (t *TypeScriptify) WithLineEnding(s string) *TypeScriptify {
+	t.LineEnding = s
+	return t
+}
+
+func (t *TypeScriptify) WithHeader(s string) *TypeScriptify {
+	t.Header = s
+	return t
+}
+
+func (t *TypeScriptify) WithBlankLines(b bool) *TypeScriptify {
+	t.BlankLines = b
+	return t
+}
+
+func (t *TypeScriptify) WithNamespace(ns string) *TypeScriptify {
+	t.Namespace = ns
+	return t
+}
+
+func (t *TypeScriptify) WithDefaultExportNamespace(b bool) *TypeScriptify {
+	t.DefaultExportNamespace = b
+	return t
+}
+
+func (t *TypeScriptify) WithNumberCoercion(f string) *TypeScriptify {
+	t.NumberCoercion = f
+	return t
+}
+
+func (t *TypeScriptify) WithOptionalChaining(b bool) *TypeScriptify {
+	t.OptionalChaining = b
+	return t
+}
+
+func (t *TypeScriptify) WithFalseForOmittedBools(b bool) *TypeScriptify {
+	t.FalseForOmittedBools = b
+	return t
+}
+
+func (t *TypeScriptify) WithFieldOrder(o string) *TypeScriptify {
+	t.FieldOrder = o
+	return t
+}
+
+// WithSortFields sorts the fields by TypeScript property names, the same as WithFieldOrder(FieldOrderAlphabetical).
+func (t *TypeScriptify) WithSortFields(b bool) *TypeScriptify {
+	t.SortFields = b
+	return t
+}
+
+func (t *TypeScriptify) WithNullValue(v string) *TypeScriptify {
+	t.NullValue = v
+	return t
+}
+
+func (t *TypeScriptify) WithReadonlyFields(b bool) *TypeScriptify {
+	t.ReadonlyFields = b
+	return t
+}
+
+func (t *TypeScriptify) WithReadonlyContainers(b bool) *TypeScriptify {
+	t.ReadonlyContainers = b
+	return t
+}
+
+func (t *TypeScriptify) WithArrayTuples(b bool) *TypeScriptify {
+	t.ArrayTuples = b
+	return t
+}
+
+func (t *TypeScriptify) WithNullableElements(b bool) *TypeScriptify {
+	t.NullableElements = b
+	return t
+}
+
+// WithNullablePointers makes all the pointer fields nullable (`| null`), because nil pointers are serialized as null.
+// Without it, only pointers to structs, slices and arrays are nullable.
+func (t *TypeScriptify) WithNullablePointers(b bool) *TypeScriptify {
+	t.NullablePointers = b
+	return t
+}
+
+// WithNullishOptionals adds `| undefined` to the types of nullable omitempty fields (i.e. pointers with omitempty),
+// which can be null or missing. This is needed with the exactOptionalPropertyTypes TypeScript option.
+func (t *TypeScriptify) WithNullishOptionals(b bool) *TypeScriptify {
+	t.NullishOptionals = b
+	return t
+}
+
+// WithByteArraysAsStrings converts `[N]byte` fields to strings. Note that encoding/json marshals them as arrays of
+// numbers, so this is only useful if the field (or array) type has a custom (i.e. hex or base64) JSON encoding.
+func (t *TypeScriptify) WithByteArraysAsStrings(b bool) *TypeScriptify {
+	t.ByteArraysAsStrings = b
+	return t
+}
+
+func (t *TypeScriptify) WithFromPartial(b bool) *TypeScriptify {
+	t.CreateFromPartial = b
+	return t
+}
+
+func (t *TypeScriptify) WithStrictNames(b bool) *TypeScriptify {
+	t.StrictNames = b
+	return t
+}
+
+// WithAnonymousStructNames converts (nested) anonymous structs to types named by the path of the field where they are
+// first used: `OrderMetaAudit` for the Meta.Audit field of Order.
+func (t *TypeScriptify) WithAnonymousStructNames(b bool) *TypeScriptify {
+	t.AnonymousStructNames = b
+	return t
+}
+
+func (t *TypeScriptify) WithErrorOnEmpty(b bool) *TypeScriptify {
+	t.ErrorOnEmpty = b
+	return t
+}
+
+func (t *TypeScriptify) WithStrict(b bool) *TypeScriptify {
+	t.Strict = b
+	return t
+}
+
+func (t *TypeScriptify) WithSeeComments(b bool) *TypeScriptify {
+	t.SeeComments = b
+	return t
+}
+
+func (t *TypeScriptify) WithSourceComments(b bool) *TypeScriptify {
+	t.SourceComments = b
+	return t
+}
+
+func (t *TypeScriptify) WithConstructor(b bool) *TypeScriptify {
+	t.CreateConstructor = b
+	return t
+}
+
+func (t *TypeScriptify) WithIndent(i string) *TypeScriptify {
+	t.Indent = i
+	return t
+}
+
+// WithBackupDir sets the directory where the existing files are copied before being overwritten, no backups are made
+// if empty (the default).
+func (t *TypeScriptify) WithBackupDir(b string) *TypeScriptify {
+	t.BackupDir = b
+	return t
+}
+
+func (t *TypeScriptify) WithFixedBackupName(b bool) *TypeScriptify {
+	t.FixedBackupName = b
+	return t
+}
+
+func (t *TypeScriptify) WithPrefix(p string) *TypeScriptify {
+	t.Prefix = p
+	return t
+}
+
+func (t *TypeScriptify) WithSuffix(s string) *TypeScriptify {
+	t.Suffix = s
+	return t
+}
+
+func (t *TypeScriptify) Add(obj interface{}) *TypeScriptify {
+	switch ty := obj.(type) {
+	case StructType:
+		t.structTypes = append(t.structTypes, ty)
+	case *StructType:
+		t.structTypes = append(t.structTypes, *ty)
+	case reflect.Type:
+		t.AddType(ty)
+	default:
+		t.AddType(reflect.TypeOf(obj))
+	}
+	return t
+}
+
+// AddMany adds all the objects (as with Add()).
+func (t *TypeScriptify) AddMany(objs ...interface{}) *TypeScriptify {
+	for _, obj := range objs {
+		t.Add(obj)
+	}
+	return t
+}
+
+func (t *TypeScriptify) AddType(typeOf reflect.Type) *TypeScriptify {
+	t.structTypes = append(t.structTypes, StructType{Type: typeOf})
+	return t
+}
+
+func (t *TypeScriptify) AddEnum(values interface{}) *TypeScriptify {
+	if t.enums == nil {
+		t.enums = map[reflect.Type][]enumElement{}
+	}
+	items := reflect.ValueOf(values)
+	if items.Kind() == reflect.Map {
+		return t.addEnumFromMap(items)
+	}
+	if items.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("Values for %T isn't a slice", values))
+	}
+
+	var elements []enumElement
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+
+		var el enumElement
+		if item.Kind() == reflect.Struct {
+			r := reflector.New(item.Interface())
+			val, err := r.Field("Value").Get()
+			if err != nil {
+				panic(fmt.Sprint("missing Type field in ", item.Type().String()))
+			}
+			name, err := r.Field("TSName").Get()
+			if err != nil {
+				panic(fmt.Sprint("missing TSName field in ", item.Type().String()))
+			}
+			el.value = val
+			el.name = name.(string)
+		} else {
+			el.value = item.Interface()
+			if tsNamer, is := item.Interface().(TSNamer); is {
+				el.name = tsNamer.TSName()
+			} else {
+				panic(fmt.Sprint(item.Type().String(), " has no TSName method"))
+			}
+		}
+
+		elements = append(elements, el)
+	}
+	ty := reflect.TypeOf(elements[0].value)
+	t.enums[ty] = elements
+	t.enumTypes = append(t.enumTypes, EnumType{Type: ty})
+
+	return t
+}
+
+// AddImportedType marks a type as defined in another module. The type is not converted, references to it are
+// imported from `ImportPath`.
+func (t *TypeScriptify) AddImportedType(obj interface{}) *TypeScriptify {
+	if t.importedTypes == nil {
+		t.importedTypes = map[reflect.Type]bool{}
+	}
+	typeOf, is := obj.(reflect.Type)
+	if !is {
+		typeOf = reflect.TypeOf(obj)
+	}
+	t.importedTypes[typeOf] = true
+	return t
+}
+
+// addEnumFromMap adds an enum from a map of names to values. Maps are unordered, so the elements are sorted by
+// value.
+func (t *TypeScriptify) addEnumFromMap(items reflect.Value) *TypeScriptify {
+	if items.Type().Key().Kind() != reflect.String {
+		panic(fmt.Sprint("Enum map keys must be strings, not ", items.Type().Key().String()))
+	}
+
+	var elements []enumElement
+	for _, key := range items.MapKeys() {
+		elements = append(elements, enumElement{name: key.String(), value: items.MapIndex(key).Interface()})
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		a, b := reflect.ValueOf(elements[i].value), reflect.ValueOf(elements[j].value)
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if a.Int() != b.Int() {
+				return a.Int() < b.Int()
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if a.Uint() != b.Uint() {
+				return a.Uint() < b.Uint()
+			}
+		case reflect.Float32, reflect.Float64:
+			if a.Float() != b.Float() {
+				return a.Float() < b.Float()
+			}
+		default:
+			if a.String() != b.String() {
+				return a.String() < b.String()
+			}
+		}
+		return elements[i].name < elements[j].name
+	})
+
+	ty := items.Type().Elem()
+	t.enums[ty] = elements
+	t.enumTypes = append(t.enumTypes, EnumType{Type: ty})
+	return t
+}
+
+func (t *TypeScriptify) enumMemberName(el enumElement) string {
+	if t.EnumMemberTransform != nil {
+		return t.EnumMemberTransform(el.name)
+	}
+	return el.name
+}
+
+// convertEnumToConstObject converts the enum to a constant object (`as const`) and a type with all its values.
+func (t *TypeScriptify) convertEnumToConstObject(entityName string, elements []enumElement) string {
+	export := ""
+	if !t.DontExport {
+		export = "export "
+	}
+
+	result := ""
+	if t.DeclarationOnly {
+		result += fmt.Sprintf("%s%sdeclare const %s: {\n", t.indentation(0), export, entityName)
+		for _, val := range elements {
+			result += fmt.Sprintf("%sreadonly %s: %#v;\n", t.indentation(1), t.enumMemberName(val), val.value)
+		}
+		result += t.indentation(0) + "};\n"
+	} else {
+		result += fmt.Sprintf("%s%sconst %s = {\n", t.indentation(0), export, entityName)
+		for _, val := range elements {
+			result += fmt.Sprintf("%s%s: %#v,\n", t.indentation(1), t.enumMemberName(val), val.value)
+		}
+		result += t.indentation(0) + "} as const;\n"
+	}
+	result += fmt.Sprintf("%s%stype %s = typeof %s[keyof typeof %s];", t.indentation(0), export, entityName, entityName, entityName)
+	return result
+}
+
+// AddEnumValues is deprecated, use `AddEnum()`
+func (t *TypeScriptify) AddEnumValues(typeOf reflect.Type, values interface{}) *TypeScriptify {
+	t.AddEnum(values)
+	return t
+}
+
+func (t *TypeScriptify) Convert(customCode map[string]string) (string, error) {
+	if err := t.validateClassKeyword(); err != nil {
+		return "", err
+	}
+	if t.PrivateFields && t.CreateFromMethod && (t.LiteralCreateFrom || t.ExternalCreateFrom) && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly {
+		return "", fmt.Errorf("private fields can't be used with a literal createFrom (object literals can't have private fields)")
+	}
+	if t.MemoizeCreateFrom && t.CreateFromMethod && (t.LiteralCreateFrom || t.ExternalCreateFrom || t.FreezeCreateFrom) && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly {
+		return "", fmt.Errorf("a memoized createFrom can't be used with a literal, external or frozen createFrom")
+	}
+	t.alreadyConverted = make(map[reflect.Type]bool)
+	t.manifest = nil
+	t.entityTypes = map[string]reflect.Type{}
+	t.anonymousNames = map[reflect.Type]string{}
+	t.usedImports = nil
+	t.converters = nil
+	t.usesConvertValues = false
+	t.usedDecorators = nil
+	t.entityCode = map[string]string{}
+	t.dependencies = map[string][]string{}
+	t.valueDependencies = map[string]map[string]bool{}
+	t.usedNames = map[string]map[string]bool{}
+	if len(t.customCode) > 0 {
+		merged := map[string]string{}
+		for name, code := range customCode {
+			merged[name] = code
+		}
+		for name, code := range t.customCode {
+			merged[name] = code
+		}
+		customCode = merged
+	}
+	depth := 0
+
+	result := ""
+	if len(t.customImports) > 0 {
+		// Put the custom imports, i.e.: `import Decimal from 'decimal.js'`
+		for _, cimport := range t.customImports {
+			result += cimport + "\n"
+		}
+	}
+	importsEnd := len(result)
+
+	for _, enumTyp := range t.enumTypes {
+		elements := t.enums[enumTyp.Type]
+		typeScriptCode, err := t.convertEnum(depth, enumTyp.Type, elements)
+		if err != nil {
+			return "", err
+		}
+		if typeScriptCode != "" {
+			t.entityCode[t.entityName(enumTyp.Type)] = typeScriptCode
+		}
+		result += "\n" + trimBlankLines(typeScriptCode)
+	}
+
+	for _, union := range t.unions {
+		typeScriptCode := t.convertStringUnion(union)
+		t.entityCode[t.Prefix+union.name+t.Suffix] = typeScriptCode
+		result += "\n" + typeScriptCode
+	}
+
+	aliased := map[reflect.Type]bool{}
+	for _, strctTyp := range t.structTypes {
+		convert := t.convertType
+		if t.isTypeAlias(strctTyp.Type) {
+			convert = t.convertTypeAlias
+		}
+		typeScriptCode, err := convert(depth, strctTyp.Type, customCode)
+		if err != nil {
+			return "", err
+		}
+		if typeScriptCode != "" { // Empty if already converted
+			result += "\n" + trimBlankLines(typeScriptCode)
+		}
+		if strctTyp.Type.Kind() == reflect.Struct && !t.importedTypes[strctTyp.Type] && !aliased[strctTyp.Type] {
+			aliased[strctTyp.Type] = true
+			entityName := t.entityName(strctTyp.Type)
+			var aliases []string
+			if t.GeneratePatchTypes {
+				aliases = append(aliases, t.convertPatchType(entityName))
+			}
+			if t.ResponseEnvelope != "" {
+				aliases = append(aliases, t.convertResponseType(entityName))
+			}
+			for _, alias := range aliases {
+				t.entityCode[entityName] += "\n" + alias
+				result += "\n" + alias
+			}
+		}
+	}
+
+	for _, sum := range t.sumTypes {
+		for _, variant := range sum.variants {
+			typeScriptCode, err := t.convertType(depth, variant, customCode)
+			if err != nil {
+				return "", err
+			}
+			if typeScriptCode != "" {
+				result += "\n" + trimBlankLines(typeScriptCode)
+			}
+		}
+		if sum.name == "" { // Unnamed interface, the union is used inline
+			continue
+		}
+		for _, variant := range sum.variants {
+			t.addDependencies(t.Prefix+sum.name+t.Suffix, variant)
+		}
+		typeScriptCode := t.convertSumType(sum)
+		if sum.discriminator != "" && sum.iface != nil { // Used to create the fields of the interface
+			var variants []StructType
+			for _, variant := range sum.variants {
+				variants = append(variants, StructType{Type: variant})
+			}
+			name := t.Prefix + sum.name + t.Suffix
+			typeScriptCode += "\n" + t.convertDispatcher("create"+name, name, sum.discriminator, variants)
+		}
+		t.entityCode[t.Prefix+sum.name+t.Suffix] = typeScriptCode
+		result += "\n" + typeScriptCode
+	}
+
+	if t.RootUnion != "" {
+		for _, strctTyp := range t.structTypes {
+			t.addDependencies(t.RootUnion, strctTyp.Type)
+		}
+		typeScriptCode := t.convertRootUnion()
+		if t.RootUnionDiscriminator != "" {
+			typeScriptCode += "\n" + t.convertRootUnionDispatcher()
+		}
+		t.entityCode[t.RootUnion] = typeScriptCode
+		result += "\n" + typeScriptCode
+	}
+
+	if t.usesConvertValues {
+		result += "\n" + t.indentCode(tsSplitConvertValuesFunc, 0)
+	}
+
+	if t.BlankLines {
+		result = result[:importsEnd] + separateTopLevelStatements(result[importsEnd:], t.indentation(0))
+	}
+	if t.Namespace != "" {
+		result = result[:importsEnd] + t.wrapInNamespace(result[importsEnd:])
+	}
+
+	if len(t.usedImports) > 0 {
+		imports := ""
+		for _, typ := range t.usedImports {
+			entityName := t.entityName(typ)
+			importKeyword := "import"
+			if t.TypeImports && !t.usedAsValue(entityName) {
+				importKeyword = "import type"
+			}
+			imports += fmt.Sprintf("%s { %s } from '%s';\n", importKeyword, entityName, strings.ReplaceAll(t.ImportPath, "__TYPE__", entityName))
+		}
+		result = result[:importsEnd] + imports + result[importsEnd:]
+	}
+	if t.ClassValidator {
+		result = result[:importsEnd] + t.classValidatorImport() + result[importsEnd:]
+	}
+	return result, nil
+}
+
+// namespaceDepth returns the nesting level of the converted entities (1 in the Namespace).
+func (t *TypeScriptify) namespaceDepth() int {
+	if t.Namespace != "" {
+		return 1
+	}
+	return 0
+}
+
+// indentation returns the indentation of code the given number of levels deeper than the entities.
+func (t *TypeScriptify) indentation(level int) string {
+	return strings.Repeat(t.Indent, t.namespaceDepth()+level)
+}
+
+// indentCode indents the (tab indented) code the given number of levels deeper than the entities.
+func (t *TypeScriptify) indentCode(code string, level int) string {
+	lines := strings.Split(strings.ReplaceAll(code, "\t", t.Indent), "\n")
+	for n := range lines {
+		if lines[n] != "" {
+			lines[n] = t.indentation(level) + lines[n]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapInNamespace wraps the code (already indented one level deeper) in `namespace Namespace {...}` (and exports it as
+// default if DefaultExportNamespace).
+func (t *TypeScriptify) wrapInNamespace(code string) string {
+	declaration := "namespace " + t.Namespace + " {"
+	if t.DeclarationOnly {
+		declaration = "declare " + declaration
+	}
+	if !t.DontExport {
+		declaration = "export " + declaration
+	}
+
+	result := "\n" + declaration + "\n" + strings.TrimLeft(code, "\n") + "\n}"
+	if t.DefaultExportNamespace {
+		if t.BlankLines {
+			result += "\n"
+		}
+		result += "\nexport default " + t.Namespace + ";"
+	}
+	return result
+}
+
+// ConvertTypes converts the types and returns the code of every entity (struct, enum, union,...) by entity name.
+// Custom imports and imports of imported types are not included.
+func (t *TypeScriptify) ConvertTypes(customCode map[string]string) (map[string]string, error) {
+	namespace := t.Namespace
+	t.Namespace = "" // The entities are converted without the namespace (and its indentation)
+	defer func() { t.Namespace = namespace }()
+	if _, err := t.Convert(customCode); err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for name, code := range t.entityCode {
+		result[name] = trimBlankLines(code)
+	}
+	return result, nil
+}
+
+// convertPatchType creates the all-optional type of the entity (used for partial updates).
+func (t *TypeScriptify) convertPatchType(entityName string) string {
+	result := fmt.Sprintf("type %sPatch = Partial<%s>;", entityName, entityName)
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+// convertResponseType creates the type of the entity wrapped in the ResponseEnvelope.
+func (t *TypeScriptify) convertResponseType(entityName string) string {
+	envelope := t.ResponseEnvelope + "<" + entityName + ">"
+	if strings.Contains(t.ResponseEnvelope, "__TYPE__") {
+		envelope = strings.ReplaceAll(t.ResponseEnvelope, "__TYPE__", entityName)
+	}
+	result := fmt.Sprintf("type %sResponse = %s;", entityName, envelope)
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+func (t *TypeScriptify) convertStringUnion(union stringUnion) string {
+	var members []string
+	for _, value := range union.values {
+		members = append(members, fmt.Sprintf("%q", value))
+	}
+
+	result := fmt.Sprintf("type %s = %s;", t.Prefix+union.name+t.Suffix, strings.Join(members, " | "))
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+// sumTypeUnion returns the union of the sum type variants (i.e. `Circle | Square`).
+func (t *TypeScriptify) sumTypeUnion(sum sumType) string {
+	var names []string
+	for _, variant := range sum.variants {
+		names = append(names, t.entityName(variant))
+	}
+	return strings.Join(names, " | ")
+}
+
+func (t *TypeScriptify) convertSumType(sum sumType) string {
+	result := fmt.Sprintf("type %s = %s;", t.Prefix+sum.name+t.Suffix, t.sumTypeUnion(sum))
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+func (t *TypeScriptify) rootTypes() []StructType {
+	var roots []StructType
+	added := map[reflect.Type]bool{}
+	for _, strctTyp := range t.structTypes {
+		if added[strctTyp.Type] {
+			continue
+		}
+		added[strctTyp.Type] = true
+		roots = append(roots, strctTyp)
+	}
+	return roots
+}
+
+func (t *TypeScriptify) convertRootUnion() string {
+	var names []string
+	for _, strctTyp := range t.rootTypes() {
+		names = append(names, t.entityName(strctTyp.Type))
+	}
+	if len(names) == 0 {
+		names = append(names, "never")
+	}
+
+	result := fmt.Sprintf("type %s = %s;", t.RootUnion, strings.Join(names, " | "))
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+func (t *TypeScriptify) convertRootUnionDispatcher() string {
+	return t.convertDispatcher("createFromAny", t.RootUnion, t.RootUnionDiscriminator, t.rootTypes())
+}
+
+// convertDispatcher creates a function which creates the right variant of the union based on the discriminator.
+func (t *TypeScriptify) convertDispatcher(funcName, unionName, discriminatorField string, variants []StructType) string {
+	if t.DeclarationOnly {
+		result := fmt.Sprintf("declare function %s(source?: any): %s;", funcName, unionName)
+		if !t.DontExport {
+			result = "export " + result
+		}
+		return t.indentation(0) + result
+	}
+
+	discriminator := fmt.Sprintf("source[%q]", discriminatorField)
+
+	result := fmt.Sprintf("function %s(source: any = {}): %s {\n", funcName, unionName)
+	result += t.indentation(1) + "if ('string' === typeof source) source = JSON.parse(source);\n"
+	result += fmt.Sprintf("%sswitch (%s) {\n", t.indentation(1), discriminator)
+	for _, strctTyp := range variants {
+		entityName := t.entityName(strctTyp.Type)
+		value := strctTyp.DiscriminatorValue
+		if value == "" {
+			value = typeName(strctTyp.Type)
+		}
+		result += fmt.Sprintf("%scase %q:\n", t.indentation(2), value)
+		if !t.CreateInterface {
+			t.addValueDependencies(unionName, entityName)
+		}
+		switch {
+		case t.CreateInterface:
+			result += fmt.Sprintf("%sreturn source as %s;\n", t.indentation(3), entityName)
+		case t.CreateFromMethod:
+			result += fmt.Sprintf("%sreturn %s.%s(source);\n", t.indentation(3), entityName, t.createFromMethodName())
+		default:
+			result += fmt.Sprintf("%sreturn new %s(source);\n", t.indentation(3), entityName)
+		}
+	}
+	result += t.indentation(1) + "}\n"
+	result += fmt.Sprintf("%sthrow new Error(\"unknown %s: \" + %s);\n", t.indentation(1), discriminatorField, discriminator)
+	result += t.indentation(0) + "}"
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+func loadCustomCode(fileName string) (map[string]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]string), nil
+		}
+		return make(map[string]string), err
+	}
+	defer f.Close()
+
+	return ParseCustomCode(f)
+}
+
+// ParseCustomCode reads the custom code blocks (`//[Name:]` ... `//[end]`) from r, the result can be used in
+// Convert() or ConvertToWriter().
+func ParseCustomCode(r io.Reader) (map[string]string, error) {
+	result := make(map[string]string)
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return result, err
+	}
+
+	var currentName string
+	var currentValue string
+	lines := strings.Split(string(bytes), "\n")
+	for _, line := range lines {
 		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "//[") && strings.HasSuffix(trimmedLine, ":]") {
-			currentName = strings.Replace(strings.Replace(trimmedLine, "//[", "", -1), ":]", "", -1)
+		if isCustomCodeStart(trimmedLine) {
+			currentName = strings.TrimSuffix(strings.TrimPrefix(trimmedLine, CustomCodeStartPrefix), CustomCodeStartSuffix)
 			currentValue = ""
-		} else if trimmedLine == "//[end]" {
+		} else if trimmedLine == CustomCodeEnd {
 			result[currentName] = strings.TrimRight(currentValue, " \t\r\n")
 			currentName = ""
 			currentValue = ""
 		} else if len(currentName) > 0 {
-			currentValue += line + "\n"
+			currentValue += strings.TrimSuffix(line, "\r") + "\n"
 		}
 	}
 
 	return result, nil
 }
 
+func isCustomCodeStart(trimmedLine string) bool {
+	return strings.HasPrefix(trimmedLine, CustomCodeStartPrefix) && strings.HasSuffix(trimmedLine, CustomCodeStartSuffix)
+}
+
+// customCodeBlock returns the custom code between the markers.
+func customCodeBlock(indent, entityName, code string) string {
+	return indent + CustomCodeStartPrefix + entityName + CustomCodeStartSuffix + "\n" + code + "\n\n" + indent + CustomCodeEnd + "\n"
+}
+
 func (t TypeScriptify) backup(fileName string) error {
 	fileIn, err := os.Open(fileName)
 	if err != nil {
@@ -396,6 +1662,9 @@ func (t TypeScriptify) backup(fileName string) error {
 	}
 
 	_, backupFn := path.Split(fmt.Sprintf("%s-%s.backup", fileName, time.Now().Format("2006-01-02T15_04_05.99")))
+	if t.FixedBackupName {
+		_, backupFn = path.Split(fileName + ".backup")
+	}
 	if t.BackupDir != "" {
 		backupFn = path.Join(t.BackupDir, backupFn)
 	}
@@ -422,28 +1691,319 @@ func (t TypeScriptify) ConvertToFile(fileName string) error {
 	}
 	defer f.Close()
 
-	converted, err := t.Convert(customCode)
-	if err != nil {
+	return t.ConvertToWriter(f, customCode)
+}
+
+// ConvertIntoFile replaces the code between the GeneratedStart and GeneratedEnd markers in an existing (hand-maintained)
+// file with the converted code, everything outside the markers is kept as it is. If the markers are missing, they are
+// appended (with the code) at the end of the file. A GeneratedStart marker without a GeneratedEnd is an error.
+func (t TypeScriptify) ConvertIntoFile(fileName string) error {
+	if len(t.BackupDir) > 0 {
+		err := t.backup(fileName)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	content := string(existing)
+
+	var before, after string
+	start := strings.Index(content, GeneratedStart)
+	end := -1
+	if start >= 0 {
+		end = strings.Index(content[start:], GeneratedEnd)
+		if end < 0 {
+			return fmt.Errorf("%s has a %s marker without a %s marker", fileName, GeneratedStart, GeneratedEnd)
+		}
+	}
+	if end >= 0 {
+		before, after = content[:start], content[start+end+len(GeneratedEnd):]
+	} else if content != "" { // No markers, append them
+		before = strings.TrimRight(content, "\r\n") + t.lineEnding() + t.lineEnding()
+	}
 
-	if _, err := f.WriteString("/* Do not change, this code is generated from Golang structs */\n\n"); err != nil {
+	customCode, err := ParseCustomCode(strings.NewReader(content))
+	if err != nil {
 		return err
 	}
-	if _, err := f.WriteString(converted); err != nil {
+	var code bytes.Buffer
+	if err := t.ConvertToWriter(&code, customCode); err != nil {
 		return err
 	}
+	if after == "" {
+		after = t.lineEnding()
+	}
+
+	return ioutil.WriteFile(fileName, []byte(before+GeneratedStart+t.lineEnding()+code.String()+GeneratedEnd+after), 0644)
+}
+
+func (t TypeScriptify) lineEnding() string {
+	if t.LineEnding == "" {
+		return "\n"
+	}
+	return t.LineEnding
+}
+
+// ConvertToWriter writes the converted code (with the Header) to w.
+func (t *TypeScriptify) ConvertToWriter(w io.Writer, customCode map[string]string) error {
+	converted, err := t.Convert(customCode)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	result := t.fileHeader(converted)
+	if result == "" { // Without a header, start with the code
+		converted = strings.TrimLeft(converted, "\n")
+	}
+	result += converted
+
+	_, err = io.WriteString(w, t.withLineEndings(result))
+	return err
+}
+
+// header returns the Header followed by a blank line, or nothing if there is no header.
+func (t TypeScriptify) header() string {
+	if t.Header == "" {
+		return ""
+	}
+	return t.Header + "\n\n"
+}
+
+// fileHeader returns the header of a file with the code, followed by the hash comment of the code (with HashComment).
+func (t TypeScriptify) fileHeader(code string) string {
+	result := t.header()
+	if t.HashComment {
+		result += fmt.Sprintf("/* Hash: %x */\n", sha256.Sum256([]byte(code)))
+	}
+	return result
+}
+
+// withLineEndings ends the code with exactly one newline, and converts the newlines (also in custom code) to LineEnding.
+func (t TypeScriptify) withLineEndings(code string) string {
+	code = strings.TrimRight(strings.ReplaceAll(code, "\r\n", "\n"), "\n") + "\n"
+	if t.LineEnding != "" && t.LineEnding != "\n" {
+		code = strings.ReplaceAll(code, "\n", t.LineEnding)
+	}
+	return code
 }
 
 type TSNamer interface {
 	TSName() string
 }
 
+// TSDocer is implemented by structs with a documentation, which is added as JSDoc above the class (or interface).
+type TSDocer interface {
+	TSDoc() string
+}
+
+// TSTyper is implemented by types with a custom TypeScript type, and the transformation used when creating the field (as
+// in the ts_transform tag, i.e. "new Decimal(__VALUE__)", empty if the value is used as it is).
+type TSTyper interface {
+	TypeScriptType() (tsType string, createFromExpr string)
+}
+
+// typeDoc returns the JSDoc of the type (with the TSDoc() documentation and the @source comment), empty if none.
+func (t *TypeScriptify) typeDoc(typeOf reflect.Type) string {
+	var lines []string
+	if docer, is := reflect.New(typeOf).Interface().(TSDocer); is {
+		lines = append(lines, docer.TSDoc())
+	}
+	if t.SourceComments {
+		lines = append(lines, "@source "+qualifiedTypeName(typeOf))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return jsDoc(strings.Join(lines, "\n"), t.indentation(0))
+}
+
+func (t *TypeScriptify) entityName(typeOf reflect.Type) string {
+	return t.Prefix + stripTypeNameSuffix(structName(typeOf, t.anonymousNames), t.StripSuffix) + t.Suffix
+}
+
+// nameAnonymousStruct synthesizes the name of the anonymous struct (contained in the field type) from the current
+// path, unless already named by the first field using it.
+func (t *TypeScriptify) nameAnonymousStruct(fieldType reflect.Type) {
+	if typ, _ := containedStruct(fieldType); typ != nil && typeName(typ) == "" {
+		if _, found := t.anonymousNames[typ]; !found {
+			t.anonymousNames[typ] = strings.Join(t.path, "")
+		}
+	}
+}
+
+// registerEntityName remembers which type is converted to the entity name. Two types converted to the same name (from
+// different packages, or because of the stripped suffix) are an error.
+func (t *TypeScriptify) registerEntityName(typeOf reflect.Type, entityName string) error {
+	if other, found := t.entityTypes[entityName]; found && other != typeOf {
+		if typeName(other) != "" || typeName(typeOf) != "" {
+			return fmt.Errorf("%s and %s are both converted to %s", qualifiedTypeName(other), qualifiedTypeName(typeOf), entityName)
+		}
+	}
+	t.entityTypes[entityName] = typeOf
+	return nil
+}
+
+// typeMappings returns the TypeScript types of the kinds. Interfaces are InterfaceType ("any" if not set), unless
+// mapped with AddTypeMapping.
+func (t *TypeScriptify) typeMappings() map[reflect.Kind]string {
+	if _, found := t.kinds[reflect.Interface]; found {
+		return t.kinds
+	}
+	types := make(map[reflect.Kind]string, len(t.kinds)+1)
+	for kind, tsType := range t.kinds {
+		types[kind] = tsType
+	}
+	types[reflect.Interface] = "any"
+	if t.InterfaceType != "" {
+		types[reflect.Interface] = t.InterfaceType
+	}
+	return types
+}
+
+func (t *TypeScriptify) isNamedScalar(typeOf reflect.Type) bool {
+	if typeOf.Name() == "" || typeOf.PkgPath() == "" || typeOf.Kind() == reflect.Interface {
+		return false
+	}
+	_, found := t.kinds[typeOf.Kind()]
+	return found
+}
+
+func (t *TypeScriptify) convertNamedScalar(depth int, typeOf reflect.Type) (string, error) {
+	if _, found := t.alreadyConverted[typeOf]; found { // Already converted
+		return "", nil
+	}
+	t.logf(depth, "Converting named scalar %s", typeOf.String())
+	t.alreadyConverted[typeOf] = true
+
+	entityName := t.entityName(typeOf)
+	if err := t.registerEntityName(typeOf, entityName); err != nil {
+		return "", err
+	}
+	tsType := t.kinds[typeOf.Kind()]
+	if t.BrandedScalars {
+		tsType += fmt.Sprintf(" & { readonly __brand: '%s' }", entityName)
+	}
+	result := fmt.Sprintf("type %s = %s;", entityName, tsType)
+	if !t.DontExport {
+		result = "export " + result
+	}
+	result = t.typeDoc(typeOf) + t.indentation(0) + result
+	t.entityCode[entityName] = result
+	return result, nil
+}
+
+// isTypeAlias checks if the (top-level) type is a named non-struct type, converted to a `type` alias.
+func (t *TypeScriptify) isTypeAlias(typeOf reflect.Type) bool {
+	if typeOf.Name() == "" {
+		return false
+	}
+	switch typeOf.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return t.isNamedScalar(typeOf)
+}
+
+func (t *TypeScriptify) convertTypeAlias(depth int, typeOf reflect.Type, customCode map[string]string) (string, error) {
+	if t.isNamedScalar(typeOf) {
+		return t.convertNamedScalar(depth, typeOf)
+	}
+	if _, found := t.alreadyConverted[typeOf]; found { // Already converted
+		return "", nil
+	}
+	t.logf(depth, "Converting type alias %s", typeOf.String())
+	t.alreadyConverted[typeOf] = true
+
+	entityName := t.entityName(typeOf)
+	if err := t.registerEntityName(typeOf, entityName); err != nil {
+		return "", err
+	}
+
+	nested := ""
+	if elem, _ := containedStruct(typeOf); elem != nil {
+		typeScriptChunk, err := t.convertType(depth+1, elem, customCode)
+		if err != nil {
+			return "", err
+		}
+		if typeScriptChunk != "" {
+			nested = typeScriptChunk + "\n"
+		}
+	}
+
+	builder := typeScriptClassBuilder{
+		types:              t.typeMappings(),
+		enums:              t.enums,
+		anonymousNames:     t.anonymousNames,
+		prefix:             t.Prefix,
+		suffix:             t.Suffix,
+		stripSuffix:        t.StripSuffix,
+		rawMessageType:     t.rawMessageType(),
+		readonlyContainers: t.ReadonlyContainers,
+		arrayTuples:        t.ArrayTuples,
+		containerTypes:     t.containerTypes,
+	}
+	var tsType string
+	var err error
+	if typeOf.Kind() == reflect.Map {
+		tsType, err = builder.recordType(typeOf)
+	} else {
+		tsType, err = builder.typeScriptType(typeOf)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("type %s = %s;", entityName, tsType)
+	if !t.DontExport {
+		result = "export " + result
+	}
+	result = t.typeDoc(typeOf) + t.indentation(0) + result
+	t.entityCode[entityName] = result
+	return nested + result, nil
+}
+
+// convertInterface creates an (empty) TS interface for a Golang interface. Golang methods can't be converted, but the
+// members can be added as custom code.
+func (t *TypeScriptify) convertInterface(depth int, typeOf reflect.Type, customCode map[string]string) (string, error) {
+	if _, found := t.alreadyConverted[typeOf]; found { // Already converted
+		return "", nil
+	}
+	t.logf(depth, "Converting interface %s", typeOf.String())
+	t.alreadyConverted[typeOf] = true
+
+	entityName := t.entityName(typeOf)
+	if err := t.registerEntityName(typeOf, entityName); err != nil {
+		return "", err
+	}
+
+	result := "interface " + entityName + " {\n"
+	if !t.DontExport {
+		result = "export " + result
+	}
+	result = t.typeDoc(typeOf) + t.indentation(0) + result
+	for _, signature := range t.methodSignatures[entityName] {
+		result += t.indentation(1) + strings.TrimSuffix(strings.TrimSpace(signature), ";") + ";\n"
+	}
+	if code := customCode[entityName]; t.hasCustomCode(code) {
+		result += customCodeBlock(t.indentation(1), entityName, code)
+	}
+	result += t.indentation(0) + "}"
+	t.entityCode[entityName] = result
+	return result, nil
+}
+
+// hasCustomCode checks if the custom code block is written (with OmitEmptyCustomBlocks, only if it contains code).
+func (t *TypeScriptify) hasCustomCode(code string) bool {
+	if t.OmitEmptyCustomBlocks {
+		return strings.TrimSpace(code) != ""
+	}
+	return len(code) != 0
+}
+
 func (t *TypeScriptify) convertEnum(depth int, typeOf reflect.Type, elements []enumElement) (string, error) {
 	t.logf(depth, "Converting enum %s", typeOf.String())
 	if _, found := t.alreadyConverted[typeOf]; found { // Already converted
@@ -451,54 +2011,341 @@ func (t *TypeScriptify) convertEnum(depth int, typeOf reflect.Type, elements []e
 	}
 	t.alreadyConverted[typeOf] = true
 
-	entityName := t.Prefix + typeOf.Name() + t.Suffix
-	result := "enum " + entityName + " {\n"
+	entityName := t.entityName(typeOf)
+	if err := t.registerEntityName(typeOf, entityName); err != nil {
+		return "", err
+	}
+	if t.EnumStyle == EnumStyleConstObject {
+		return t.typeDoc(typeOf) + t.convertEnumToConstObject(entityName, elements) + t.convertEnumNames(entityName, elements), nil
+	}
+
+	result := "enum " + entityName + " {\n"
+	if t.EnumStyle == EnumStyleConstEnum {
+		result = "const " + result
+	}
+	if t.DeclarationOnly {
+		result = "declare " + result
+	}
+
+	for _, val := range elements {
+		result += fmt.Sprintf("%s%s = %#v,\n", t.indentation(1), t.enumMemberName(val), val.value)
+	}
+
+	result += t.indentation(0) + "}"
+
+	if !t.DontExport {
+		result = "export " + result
+	}
+
+	return t.typeDoc(typeOf) + t.indentation(0) + result + t.convertEnumNames(entityName, elements), nil
+}
+
+// convertEnumNames returns the reverse lookup map (values to member names) of numeric enums with EnumNames, i.e. for
+// displaying the values. Only the first member with a value is used.
+func (t *TypeScriptify) convertEnumNames(entityName string, elements []enumElement) string {
+	if !t.EnumNames || len(elements) == 0 || reflect.ValueOf(elements[0].value).Kind() == reflect.String {
+		return ""
+	}
+	export := ""
+	if !t.DontExport {
+		export = "export "
+	}
+	if t.DeclarationOnly {
+		return fmt.Sprintf("\n%s%sdeclare const %sNames: Readonly<Record<%s, string>>;", t.indentation(0), export, entityName, entityName)
+	}
+
+	result := fmt.Sprintf("\n%s%sconst %sNames: Readonly<Record<%s, string>> = {\n", t.indentation(0), export, entityName, entityName)
+	seen := map[string]bool{}
+	for _, val := range elements {
+		value := fmt.Sprintf("%#v", val.value)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		result += fmt.Sprintf("%s%s: %q,\n", t.indentation(1), value, t.enumMemberName(val))
+	}
+	return result + t.indentation(0) + "};"
+}
+
+func (t *TypeScriptify) getFieldOptions(structType reflect.Type, field reflect.StructField) TypeOptions {
+	// By default use options defined by tags:
+	opts := TypeOptions{TSTransform: field.Tag.Get(tsTransformTag), TSType: field.Tag.Get(tsType)}
+
+	// Or by the field type:
+	if typer, is := reflect.New(field.Type).Interface().(TSTyper); is && opts.TSType == "" && opts.TSTransform == "" {
+		opts.TSType, opts.TSTransform = typer.TypeScriptType()
+	}
+
+	overrides := []TypeOptions{}
+
+	// But there is maybe an struct-specific override:
+	for _, strct := range t.structTypes {
+		if strct.FieldOptions == nil {
+			continue
+		}
+		if strct.Type == structType {
+			if fldOpts, found := strct.FieldOptions[field.Type]; found {
+				overrides = append(overrides, fldOpts)
+			}
+		}
+	}
+
+	if fldOpts, found := t.fieldTypeOptions[field.Type]; found {
+		overrides = append(overrides, fldOpts)
+	}
+
+	for _, o := range overrides {
+		if o.TSTransform != "" {
+			opts.TSTransform = o.TSTransform
+		}
+		if o.TSType != "" {
+			opts.TSType = o.TSType
+		}
+	}
+
+	return opts
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// sourceType returns the type of the createFrom() and constructor parameter, `__TYPE__` is replaced with the entity name.
+func (t *TypeScriptify) sourceType(entityName string) string {
+	sourceType := "any"
+	if t.SourceType != "" {
+		sourceType = strings.ReplaceAll(t.SourceType, "__TYPE__", entityName)
+	}
+	if t.ImmutableSource && !strings.HasPrefix(sourceType, "Readonly<") {
+		sourceType = "Readonly<" + sourceType + ">"
+	}
+	return sourceType
+}
+
+func (t *TypeScriptify) timeType() string {
+	if t.TimeType == "" || t.TimeType == "string" {
+		if t.TimeAsDate { // Deprecated, an explicit TimeType is used instead
+			return "Date"
+		}
+		return "string"
+	}
+	return t.TimeType
+}
+
+// timeFieldOptions returns the options for converting time.Time (and slices of time.Time) fields to the time type.
+// Only "Date" needs a transformation.
+func (t *TypeScriptify) timeFieldOptions(typ reflect.Type, isPtr bool) (TypeOptions, bool) {
+	elemType, arrayDepth := arrayElem(typ)
+	if elemType != timeType {
+		return TypeOptions{}, false
+	}
+	tsType := t.timeType()
+	if tsType != "Date" {
+		return TypeOptions{TSType: arrayType(tsType, arrayDepth, t.ReadonlyContainers)}, true
+	}
+	if arrayDepth == 0 && !isPtr {
+		return TypeOptions{TSType: "Date", TSTransform: "new Date(__VALUE__)"}, true
+	}
+
+	transform := elementsTransform(arrayDepth, arrayDepth == 0, "new Date(%s)")
+	return TypeOptions{TSType: arrayType("Date", arrayDepth, t.ReadonlyContainers), TSTransform: transform}, true
+}
+
+// looseDefaultValue returns the default value of missing fields with LooseNullability. Structs and enums have no
+// sensible zero value, so they stay undefined.
+func (t *TypeScriptify) looseDefaultValue(typ reflect.Type, zero string) string {
+	if _, isEnum := t.enums[typ]; isEnum || typ.Kind() == reflect.Struct {
+		return ""
+	}
+	return zero
+}
+
+func (t *TypeScriptify) createFromMethodName() string {
+	if t.CreateFromMethodName == "" {
+		return "createFrom"
+	}
+	return t.CreateFromMethodName
+}
+
+func (t *TypeScriptify) classKeyword() string {
+	if t.ClassKeyword == "" {
+		return "class"
+	}
+	return t.ClassKeyword
+}
+
+// abstractClasses is true if the classes are abstract (i.e. `WithClassKeyword("abstract class")`) and can't be
+// instantiated with `new`.
+func (t *TypeScriptify) abstractClasses() bool {
+	return strings.HasPrefix(strings.TrimSpace(t.classKeyword()), "abstract")
+}
+
+// externalClasses is true if the classes are created (from JSON) by the standalone createXxx() functions, so they have
+// only the declarations (without the constructor and convertValues()).
+func (t *TypeScriptify) externalClasses() bool {
+	return t.CreateFromMethod && t.ExternalCreateFrom && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly
+}
+
+// instanceCreation returns the function (or the constructor with new) creating the class instance from the source.
+func (t *TypeScriptify) instanceCreation(entityName string) string {
+	if t.externalClasses() {
+		return "create" + entityName
+	}
+	return "new " + entityName
+}
 
-	for _, val := range elements {
-		result += fmt.Sprintf("%s%s = %#v,\n", t.Indent, val.name, val.value)
+// validateClassKeyword returns an error if the generated code would instantiate abstract classes. They can be created
+// only with a literal (or external) createFrom, which is used for the nested classes, too.
+func (t *TypeScriptify) validateClassKeyword() error {
+	if !t.abstractClasses() || t.CreateInterface || t.DeclarationOnly {
+		return nil
+	}
+	literal := t.CreateFromMethod && (t.LiteralCreateFrom || t.ExternalCreateFrom)
+	if !literal && (t.CreateConstructor || t.CreateFromMethod || len(t.sumTypes) > 0) {
+		return fmt.Errorf("%q can't be instantiated, use it with WithLiteralCreateFrom(true) (or WithExternalCreateFrom(true))", t.classKeyword())
 	}
+	if t.CreateClone && t.CreateConstructor {
+		return fmt.Errorf("%q can't be instantiated, use it without WithClone(true)", t.classKeyword())
+	}
+	return nil
+}
 
-	result += "}"
+func (t *TypeScriptify) rawMessageType() string {
+	if t.RawMessageType == "" {
+		return "any"
+	}
+	return t.RawMessageType
+}
 
-	if !t.DontExport {
-		result = "export " + result
+// rawMessageFieldOptions returns the options for json.RawMessage (and slices of json.RawMessage) fields, the raw JSON is
+// used as it is. Must be checked before []byte, which is a base64 string.
+func (t *TypeScriptify) rawMessageFieldOptions(typ reflect.Type) (TypeOptions, bool) {
+	elemType, arrayDepth := arrayElem(typ)
+	if elemType != rawMessageType {
+		return TypeOptions{}, false
 	}
+	return TypeOptions{TSType: arrayType(t.rawMessageType(), arrayDepth, t.ReadonlyContainers)}, true
+}
 
-	return result, nil
+// sumTypeFor returns the sum type added for the Golang interface (with AddUnion or AddUnionWithDiscriminator).
+func (t *TypeScriptify) sumTypeFor(typ reflect.Type) (sumType, bool) {
+	if typ.Kind() != reflect.Interface {
+		return sumType{}, false
+	}
+	for _, sum := range t.sumTypes {
+		if sum.iface == typ {
+			return sum, true
+		}
+	}
+	return sumType{}, false
 }
 
-func (t *TypeScriptify) getFieldOptions(structType reflect.Type, field reflect.StructField) TypeOptions {
-	// By default use options defined by tags:
-	opts := TypeOptions{TSTransform: field.Tag.Get(tsTransformTag), TSType: field.Tag.Get(tsType)}
+// addValueDependencies remembers the entities used as values (and not only as types) in the code of the entity.
+func (t *TypeScriptify) addValueDependencies(entityName string, names ...string) {
+	if t.valueDependencies[entityName] == nil {
+		t.valueDependencies[entityName] = map[string]bool{}
+	}
+	for _, name := range names {
+		t.valueDependencies[entityName][name] = true
+	}
+}
 
-	overrides := []TypeOptions{}
+// addUsedNames remembers the functions and constants (of other entities) used in the code of the entity.
+func (t *TypeScriptify) addUsedNames(entityName string, names ...string) {
+	if t.usedNames[entityName] == nil {
+		t.usedNames[entityName] = map[string]bool{}
+	}
+	for _, name := range names {
+		t.usedNames[entityName][name] = true
+	}
+}
 
-	// But there is maybe an struct-specific override:
-	for _, strct := range t.structTypes {
-		if strct.FieldOptions == nil {
-			continue
+// usedAsValue checks if the entity is used as a value (and not only as a type) in any converted entity.
+func (t *TypeScriptify) usedAsValue(name string) bool {
+	for _, names := range t.valueDependencies {
+		if names[name] {
+			return true
 		}
-		if strct.Type == structType {
-			if fldOpts, found := strct.FieldOptions[field.Type]; found {
-				overrides = append(overrides, fldOpts)
+	}
+	return false
+}
+
+// addDependencies remembers the entities used in the type (or its elements) as dependencies of the entity.
+func (t *TypeScriptify) addDependencies(entityName string, typ reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		t.addDependencies(entityName, typ.Elem())
+		return
+	case reflect.Map:
+		t.addDependencies(entityName, typ.Key())
+		t.addDependencies(entityName, typ.Elem())
+		return
+	case reflect.Interface:
+		if sum, found := t.sumTypeFor(typ); found {
+			if sum.name != "" {
+				t.dependencies[entityName] = append(t.dependencies[entityName], t.Prefix+sum.name+t.Suffix)
+				return
 			}
+			for _, variant := range sum.variants {
+				t.addDependencies(entityName, variant)
+			}
+			return
 		}
 	}
-
-	if fldOpts, found := t.fieldTypeOptions[field.Type]; found {
-		overrides = append(overrides, fldOpts)
+	if typeName(typ) != "" {
+		t.dependencies[entityName] = append(t.dependencies[entityName], t.entityName(typ))
 	}
+}
 
-	for _, o := range overrides {
-		if o.TSTransform != "" {
-			opts.TSTransform = o.TSTransform
-		}
-		if o.TSType != "" {
-			opts.TSType = o.TSType
+// sumTypeFieldOptions returns the options for fields (or slices) of interfaces with a sum type, every element is
+// created with the sum type dispatcher (its name is returned, if used).
+func (t *TypeScriptify) sumTypeFieldOptions(typ reflect.Type) (TypeOptions, string, bool) {
+	elemType, arrayDepth := arrayElem(typ)
+	sum, found := t.sumTypeFor(elemType)
+	if !found {
+		return TypeOptions{}, "", false
+	}
+	if sum.name == "" {
+		union := t.sumTypeUnion(sum)
+		if arrayDepth > 0 && !t.ReadonlyContainers {
+			union = "(" + union + ")"
 		}
+		return TypeOptions{TSType: arrayType(union, arrayDepth, t.ReadonlyContainers)}, "", true
+	}
+	name := t.Prefix + sum.name + t.Suffix
+	if sum.discriminator == "" {
+		return TypeOptions{TSType: arrayType(name, arrayDepth, t.ReadonlyContainers)}, "", true
 	}
+	dispatcher := "create" + name
+	transform := elementsTransform(arrayDepth, true, dispatcher+"(%s)") // Nil interfaces are serialized as null
+	return TypeOptions{TSType: arrayType(name, arrayDepth, t.ReadonlyContainers), TSTransform: transform}, dispatcher, true
+}
 
-	return opts
+// isStringEncoded checks if the field has the `,string` json option, which (for scalar fields) means that the value
+// is encoded as a JSON string.
+func isStringEncoded(field reflect.StructField) bool {
+	switch field.Type.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return false
+	}
+	return hasJSONOption(field, "string")
+}
+
+// hasJSONOption checks if the json tag of the field has the option (i.e. "omitempty").
+func hasJSONOption(field reflect.StructField, option string) bool {
+	jsonTagParts := strings.Split(field.Tag.Get("json"), ",")
+	// The first part is the field name, the options follow:
+	for _, opt := range jsonTagParts[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
 }
 
 func (t *TypeScriptify) getJSONFieldName(field reflect.StructField, isPtr bool) string {
@@ -507,69 +2354,306 @@ func (t *TypeScriptify) getJSONFieldName(field reflect.StructField, isPtr bool)
 	if len(jsonTag) > 0 {
 		jsonTagParts := strings.Split(jsonTag, ",")
 		if len(jsonTagParts) > 0 {
-			jsonFieldName = strings.Trim(jsonTagParts[0], t.Indent)
+			jsonFieldName = strings.TrimSpace(jsonTagParts[0])
 		}
-		hasOmitEmpty := false
-		ignored := false
-		for _, t := range jsonTagParts {
-			if t == "" {
-				break
-			}
-			if t == "omitempty" {
-				hasOmitEmpty = true
-				break
-			}
-			if t == "-" {
-				ignored = true
-				break
-			}
+		ignored := jsonTagParts[0] == "-"
+		optional := isPtr || hasJSONOption(field, "omitempty") || t.LooseNullability
+		// The ts_optional tag has precedence over omitempty and pointers:
+		switch field.Tag.Get(tsOptionalTag) {
+		case "true":
+			optional = true
+		case "false":
+			optional = false
 		}
-		if !ignored && isPtr || hasOmitEmpty {
+		if jsonFieldName != "" && !ignored && optional {
 			jsonFieldName = fmt.Sprintf("%s?", jsonFieldName)
 		}
 	}
 	return jsonFieldName
 }
 
+// jsonFieldNames returns the JSON names of all (also embedded) fields of the struct, except for ts_inline maps.
+func (t *TypeScriptify) jsonFieldNames(typeOf reflect.Type) []string {
+	var names []string
+	for _, field := range deepFields(typeOf) {
+		isPtr := field.Type.Kind() == reflect.Ptr
+		jsonFieldName := t.getJSONFieldName(field, isPtr)
+		if jsonFieldName == "" || jsonFieldName == "-" || field.Tag.Get(tsInlineTag) == "true" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(jsonFieldName, "?"))
+	}
+	return names
+}
+
 func (t *TypeScriptify) convertType(depth int, typeOf reflect.Type, customCode map[string]string) (string, error) {
 	if _, found := t.alreadyConverted[typeOf]; found { // Already converted
 		return "", nil
 	}
+	t.alreadyConverted[typeOf] = true
+
+	if t.importedTypes[typeOf] {
+		t.logf(depth, "Importing type %s", typeOf.String())
+		t.usedImports = append(t.usedImports, typeOf)
+		return "", nil
+	}
 	t.logf(depth, "Converting type %s", typeOf.String())
+	if len(t.path) == 0 {
+		t.path = []string{structName(typeOf, t.anonymousNames)}
+		defer func() { t.path = nil }()
+	}
+	if t.StrictNames && structName(typeOf, t.anonymousNames) == "" {
+		return "", fmt.Errorf("type without name (%s) in %s", typeOf.String(), strings.Join(t.path, "."))
+	}
 
-	t.alreadyConverted[typeOf] = true
+	entityName := t.entityName(typeOf)
+	if err := t.registerEntityName(typeOf, entityName); err != nil {
+		return "", err
+	}
+
+	// Embedded structs which are extended instead of flattened:
+	excluded := map[int]bool{}
+	var bases []reflect.Type
+	if t.Inheritance && !t.ReadonlyTypeAlias {
+		indexes, types := embeddedStructs(typeOf)
+		if !t.CreateInterface && len(indexes) > 1 { // A class can extend only one class
+			indexes, types = indexes[:1], types[:1]
+		}
+		for n := range indexes {
+			excluded[indexes[n]] = true
+		}
+		bases = types
+	}
+	var baseNames []string
+	for _, base := range bases {
+		baseNames = append(baseNames, t.entityName(base))
+		t.addDependencies(entityName, base)
+	}
+
+	// Embedded named interfaces which are implemented:
+	var interfaces []reflect.Type
+	var interfaceNames []string
+	if t.ImplementInterfaces && !t.ReadonlyTypeAlias {
+		for _, f := range embeddedInterfaces(typeOf) {
+			if f.Type.Name() != "" {
+				interfaces = append(interfaces, f.Type)
+				interfaceNames = append(interfaceNames, t.entityName(f.Type))
+				t.addDependencies(entityName, f.Type)
+			}
+		}
+	}
+	if t.CreateInterface { // Interfaces can only extend other interfaces
+		baseNames, interfaceNames = append(baseNames, interfaceNames...), nil
+	}
 
-	entityName := t.Prefix + typeOf.Name() + t.Suffix
+	extends := ""
+	if len(baseNames) > 0 {
+		extends = " extends " + strings.Join(baseNames, ", ")
+	}
+	if !t.CreateInterface && len(bases) > 0 { // A class extends the (class) value
+		t.addValueDependencies(entityName, baseNames...)
+	}
+	if len(interfaceNames) > 0 {
+		extends += " implements " + strings.Join(interfaceNames, ", ")
+	}
+
+	nested := "" // Code of the types used in this one
 	result := ""
-	if t.CreateInterface {
-		result += fmt.Sprintf("interface %s {\n", entityName)
+	if t.ReadonlyTypeAlias {
+		result += fmt.Sprintf("type %s = Readonly<{\n", entityName)
+	} else if t.CreateInterface {
+		result += fmt.Sprintf("interface %s%s {\n", entityName, extends)
+	} else if t.DeclarationOnly {
+		result += fmt.Sprintf("declare %s %s%s {\n", t.classKeyword(), entityName, extends)
 	} else {
-		result += fmt.Sprintf("class %s {\n", entityName)
+		result += fmt.Sprintf("%s %s%s {\n", t.classKeyword(), entityName, extends)
 	}
 	if !t.DontExport {
 		result = "export " + result
 	}
+	result = t.typeDoc(typeOf) + t.indentation(0) + result
 	builder := typeScriptClassBuilder{
-		types:  t.kinds,
-		indent: t.Indent,
-		prefix: t.Prefix,
-		suffix: t.Suffix,
+		types:              t.typeMappings(),
+		enums:              t.enums,
+		anonymousNames:     t.anonymousNames,
+		indent:             t.Indent,
+		depth:              t.namespaceDepth(),
+		prefix:             t.Prefix,
+		suffix:             t.Suffix,
+		stripSuffix:        t.StripSuffix,
+		quoteNames:         t.QuoteAllPropertyNames,
+		explicitUndefined:  t.ExplicitUndefined,
+		trailingCommas:     t.TrailingCommas,
+		nameTransform:      t.FieldNameTransform,
+		readonlyFields:     t.ReadonlyFields,
+		seeComments:        t.SeeComments,
+		nullValue:          t.NullValue,
+		numberCoercion:     t.NumberCoercion,
+		optionalChaining:   t.OptionalChaining,
+		rawMessageType:     t.rawMessageType(),
+		readonlyContainers: t.ReadonlyContainers,
+		arrayTuples:        t.ArrayTuples,
+		containerTypes:     t.containerTypes,
+		privateFields:      t.PrivateFields && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly,
+		definiteFields:     t.externalClasses(),
+	}
+
+	if t.WarnEmbeddedInterfaces && t.Logf != nil {
+		for _, f := range embeddedInterfaces(typeOf) {
+			t.Logf("embedded interface %s in %s is ignored", f.Type.String(), typeOf.String())
+		}
+	}
+
+	for _, base := range bases {
+		t.logf(depth, "- extends %s", base.String())
+		typeScriptChunk, err := t.convertType(depth+1, base, customCode)
+		if err != nil {
+			return "", err
+		}
+		if typeScriptChunk != "" {
+			nested = typeScriptChunk + "\n" + nested
+		}
+		builder.createFromMethodBody = append(builder.createFromMethodBody, fmt.Sprint(builder.indentation(3), "...", t.entityName(base), ".", t.createFromMethodName(), "(source),"))
+		builder.converterBody = append(builder.converterBody, fmt.Sprint(builder.converterIndentation(), "...create", t.entityName(base), "(source),"))
+		builder.valueRefs = append(builder.valueRefs, t.entityName(base))
+	}
+	for _, iface := range interfaces {
+		t.logf(depth, "- implements %s", iface.String())
+		typeScriptChunk, err := t.convertInterface(depth+1, iface, customCode)
+		if err != nil {
+			return "", err
+		}
+		if typeScriptChunk != "" {
+			nested = typeScriptChunk + "\n" + nested
+		}
 	}
 
-	fields := deepFields(typeOf)
+	fieldNames := map[string]bool{}
+	properties := map[string]reflect.StructField{} // Property names (after the transformation) of the fields
+	var skipped []string                           // Names of the skipped fields
+	inlineField := ""
+	fields := t.orderFields(deepFieldsExcept(typeOf, excluded))
 	for _, field := range fields {
 		isPtr := field.Type.Kind() == reflect.Ptr
 		if isPtr {
 			field.Type = field.Type.Elem()
 		}
+		field.Type = withContainerShapes(field.Type, t.containerTypes) // Containers are converted as the declared shapes
 		jsonFieldName := t.getJSONFieldName(field, isPtr)
-		if len(jsonFieldName) == 0 || jsonFieldName == "-" {
+		inline := field.Tag.Get(tsInlineTag) == "true" // Catch-all maps are usually ignored in JSON (and marshalled manually)
+		if len(jsonFieldName) == 0 && !inline {
+			t.logSkippedField(typeOf, field, "no JSON name (untagged or empty name in the json tag)")
+			skipped = append(skipped, field.Name)
+			continue
+		}
+		if jsonFieldName == "-" && !inline {
+			t.logSkippedField(typeOf, field, `ignored with json:"-"`)
+			skipped = append(skipped, field.Name)
 			continue
 		}
+		if kind, unsupported := unsupportedKind(field.Type); unsupported && t.getFieldOptions(typeOf, field).TSType == "" {
+			if t.Strict {
+				return "", fmt.Errorf("cannot convert %s.%s: %s fields can't be represented in TypeScript (without a ts_type)", typeOf.Name(), field.Name, kind.String())
+			}
+			t.logSkippedField(typeOf, field, kind.String()+" field without ts_type")
+			skipped = append(skipped, field.Name)
+			continue
+		}
+		fieldNames[strings.TrimSuffix(jsonFieldName, "?")] = true
+		t.path = append(t.path, field.Name)
+		if t.AnonymousStructNames {
+			t.nameAnonymousStruct(field.Type)
+		}
+		if t.BlankLines {
+			builder.startFieldGroup(promotedFrom(typeOf, field))
+		}
+
+		// A (non-nil) pointer to a nil slice is serialized as null:
+		builder.nullable = isPtr && (field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array)
+		builder.optional = strings.HasSuffix(jsonFieldName, "?")
+		builder.nullish = t.NullishOptionals && isPtr && hasJSONOption(field, "omitempty")
+		builder.kind = field.Type.Kind()
+		builder.tsName = t.propertyName(field)
+		builder.readonly = field.Tag.Get(tsReadonlyTag) == "true"
+		builder.doc = field.Tag.Get(tsDocTag)
+		builder.transformBack = field.Tag.Get(tsTransformBackTag)
+		builder.keyType = field.Tag.Get(tsKeyTypeTag)
+		builder.defaultValue = ""
+		if !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly {
+			builder.defaultValue = defaultValue(field.Tag.Get(tsDefaultTag), field.Type.Kind())
+		}
+		if !inline {
+			property := builder.propertyName(strings.TrimSuffix(jsonFieldName, "?"))
+			if other, found := properties[property]; found {
+				if !t.SuffixCollidingNames {
+					return "", fmt.Errorf("%s.%s (%s) and %s.%s (%s) are both converted to the property %s", typeOf.Name(), other.Name, strings.TrimSuffix(t.getJSONFieldName(other, false), "?"), typeOf.Name(), field.Name, strings.TrimSuffix(jsonFieldName, "?"), property)
+				}
+				suffixed := property
+				for n := 2; found; n++ {
+					suffixed = fmt.Sprintf("%s%d", property, n)
+					_, found = properties[suffixed]
+				}
+				builder.tsName, property = suffixed, suffixed
+			}
+			properties[property] = field
+		}
+		builder.zeroValue = ""
+		builder.arrayLengths = nil
+		if t.ArrayTuples {
+			builder.arrayLengths = arrayLengths(field.Type)
+		}
+		builder.nullableElements = t.NullableElements && hasPointerElements(field.Type)
+		if t.TagComments {
+			builder.tag = string(field.Tag)
+		}
 
 		var err error
 		fldOpts := t.getFieldOptions(typeOf, field)
-		if fldOpts.TSTransform != "" {
+		if t.NullablePointers && isPtr && fldOpts.TSType == "" { // Custom types are used as they are
+			builder.nullable = true
+		}
+		if fldOpts.TSType == "" && isStringEncoded(field) {
+			fldOpts.TSType = "string"
+		}
+		if fldOpts.TSType == "" && t.ByteArraysAsStrings && isByteArray(field.Type) {
+			fldOpts.TSType = "string"
+		}
+		if fldOpts.TSType == "" && fldOpts.TSTransform == "" {
+			t.addDependencies(entityName, field.Type)
+			if rawOpts, is := t.rawMessageFieldOptions(field.Type); is {
+				fldOpts = rawOpts
+			} else if timeOpts, is := t.timeFieldOptions(field.Type, isPtr); is {
+				fldOpts = timeOpts
+			} else if sumOpts, dispatcher, is := t.sumTypeFieldOptions(field.Type); is {
+				fldOpts = sumOpts
+				if dispatcher != "" {
+					builder.usedNames = append(builder.usedNames, dispatcher)
+				}
+			}
+		}
+		if fldOpts.TSType == "" && fldOpts.TSTransform == "" {
+			builder.zeroValue = zeroValue(field.Type)
+		}
+		if t.LooseNullability && builder.defaultValue == "" && !isPtr && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly {
+			builder.defaultValue = t.looseDefaultValue(field.Type, builder.zeroValue)
+		}
+		if t.ClassValidator && !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly {
+			builder.decorators = t.classValidatorDecorators(field.Type, builder.optional, fldOpts)
+		}
+		if t.FalseForOmittedBools && !isPtr && field.Type.Kind() == reflect.Bool && hasJSONOption(field, "omitempty") && fldOpts.TSType == "" && fldOpts.TSTransform == "" {
+			fldOpts.TSTransform = "__VALUE__ || false" // False bools are omitted
+		}
+		if inline { // Index signature (catch-all map):
+			if inlineField != "" {
+				return "", fmt.Errorf("%s has more than one ts_inline map (%s and %s)", typeOf.Name(), inlineField, field.Name)
+			}
+			inlineField = field.Name
+			t.logf(depth, "- inline map field %s.%s", typeOf.Name(), field.Name)
+			err = builder.AddInlineMapField(field, t.jsonFieldNames(typeOf))
+		} else if field.Type.Kind() == reflect.Func { // Callbacks, only with a ts_type:
+			t.logf(depth, "- func field %s.%s", typeOf.Name(), field.Name)
+			builder.AddFuncField(jsonFieldName, fldOpts.TSType)
+		} else if fldOpts.TSTransform != "" {
 			t.logf(depth, "- simple field %s.%s", typeOf.Name(), field.Name)
 			err = builder.AddSimpleField(jsonFieldName, field, fldOpts)
 		} else if _, isEnum := t.enums[field.Type]; isEnum {
@@ -585,149 +2669,686 @@ func (t *TypeScriptify) convertType(depth int, typeOf reflect.Type, customCode m
 				return "", err
 			}
 			if typeScriptChunk != "" {
-				result = typeScriptChunk + "\n" + result
+				nested = typeScriptChunk + "\n" + nested
 			}
-			builder.AddStructField(jsonFieldName, field)
+			builder.nullable = isPtr // Nil pointers are serialized as null
+			builder.AddStructField(jsonFieldName, field, t.InstantiateMissingStructs && !isPtr)
 		} else if field.Type.Kind() == reflect.Map {
 			t.logf(depth, "- map field %s.%s", typeOf.Name(), field.Name)
-			// Also convert map key types if needed
-			var keyTypeToConvert reflect.Type
-			switch field.Type.Key().Kind() {
-			case reflect.Struct:
-				keyTypeToConvert = field.Type.Key()
-			case reflect.Ptr:
-				keyTypeToConvert = field.Type.Key().Elem()
-			}
-			if keyTypeToConvert != nil {
-				typeScriptChunk, err := t.convertType(depth+1, keyTypeToConvert, customCode)
+			// Also convert map value types if needed
+			if valueType, _ := containedStruct(field.Type.Elem()); valueType != nil {
+				typeScriptChunk, err := t.convertType(depth+1, valueType, customCode)
 				if err != nil {
 					return "", err
 				}
 				if typeScriptChunk != "" {
-					result = typeScriptChunk + "\n" + result
+					nested = typeScriptChunk + "\n" + nested
 				}
 			}
-			// Also convert map value types if needed
-			var valueTypeToConvert reflect.Type
-			switch field.Type.Elem().Kind() {
-			case reflect.Struct:
-				valueTypeToConvert = field.Type.Elem()
-			case reflect.Ptr:
-				valueTypeToConvert = field.Type.Elem().Elem()
-			}
-			if valueTypeToConvert != nil {
-				typeScriptChunk, err := t.convertType(depth+1, valueTypeToConvert, customCode)
+
+			err = builder.AddMapField(jsonFieldName, field)
+		} else if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array { // Slice:
+			elemType, arrayDepth := arrayElem(field.Type)
+
+			if elemType.Kind() == reflect.Struct { // Slice of structs:
+				t.logf(depth, "- struct slice %s.%s (%s)", typeOf.Name(), field.Name, field.Type.String())
+				typeScriptChunk, err := t.convertType(depth+1, elemType, customCode)
 				if err != nil {
 					return "", err
 				}
 				if typeScriptChunk != "" {
-					result = typeScriptChunk + "\n" + result
+					nested = typeScriptChunk + "\n" + nested
+				}
+				builder.AddArrayOfStructsField(jsonFieldName, elemType, arrayDepth)
+			} else if elemType.Kind() == reflect.Map { // Slice of maps:
+				t.logf(depth, "- map slice %s.%s (%s)", typeOf.Name(), field.Name, field.Type.String())
+				if valueType, _ := containedStruct(elemType); valueType != nil {
+					typeScriptChunk, err := t.convertType(depth+1, valueType, customCode)
+					if err != nil {
+						return "", err
+					}
+					if typeScriptChunk != "" {
+						nested = typeScriptChunk + "\n" + nested
+					}
+				}
+				err = builder.AddArrayOfMapsField(jsonFieldName, elemType, arrayDepth)
+			} else if _, isEnum := t.enums[elemType]; isEnum && fldOpts.TSType == "" { // Slice of enums:
+				t.logf(depth, "- enum slice %s.%s (%s)", typeOf.Name(), field.Name, field.Type.String())
+				err = builder.AddSimpleArrayField(jsonFieldName, elemType, arrayDepth, TypeOptions{TSType: builder.arrayType(t.entityName(elemType), arrayDepth)})
+			} else { // Slice of simple fields:
+				t.logf(depth, "- slice field %s.%s", typeOf.Name(), field.Name)
+				err = builder.AddSimpleArrayField(jsonFieldName, elemType, arrayDepth, fldOpts)
+			}
+		} else if (t.NamedScalars || t.BrandedScalars) && t.isNamedScalar(field.Type) { // Named scalar:
+			t.logf(depth, "- named scalar field %s.%s", typeOf.Name(), field.Name)
+			typeScriptChunk, err := t.convertNamedScalar(depth+1, field.Type)
+			if err != nil {
+				return "", err
+			}
+			if typeScriptChunk != "" {
+				nested = typeScriptChunk + "\n" + nested
+			}
+			err = builder.AddSimpleField(jsonFieldName, field, TypeOptions{TSType: t.entityName(field.Type)})
+			if err != nil {
+				return "", err
+			}
+		} else { // Simple field:
+			t.logf(depth, "- simple field %s.%s", typeOf.Name(), field.Name)
+			err = builder.AddSimpleField(jsonFieldName, field, fldOpts)
+		}
+		if err != nil {
+			t.logSkippedField(typeOf, field, err.Error())
+			return "", err
+		}
+		t.path = t.path[:len(t.path)-1]
+	}
+	builder.tsName = "" // The other (discriminator, computed) fields are named by the builder
+	if t.ErrorOnEmpty && len(builder.manifestFields) == 0 && len(bases) == 0 {
+		err := fmt.Errorf("%s has no exported fields with JSON names", qualifiedTypeName(typeOf))
+		if len(t.path) > 1 { // Used in a field
+			err = fmt.Errorf("%s (in %s)", err.Error(), strings.Join(t.path, "."))
+		}
+		return "", err
+	}
+
+	for _, sum := range t.sumTypes {
+		if sum.discriminator == "" || fieldNames[sum.discriminator] {
+			continue
+		}
+		for _, variant := range sum.variants {
+			if variant == typeOf {
+				t.logf(depth, "- discriminator field %s", sum.discriminator)
+				builder.AddDiscriminatorField(sum.discriminator, typeName(typeOf), !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly)
+			}
+		}
+	}
+	if t.TypeDiscriminator != "" && !fieldNames[t.TypeDiscriminator] {
+		if len(bases) > 0 { // The field can't be redeclared with another type
+			return "", fmt.Errorf("%s extends %s, the type discriminator %s can't be used with inheritance", entityName, t.entityName(bases[0]), t.TypeDiscriminator)
+		}
+		t.logf(depth, "- type discriminator field %s", t.TypeDiscriminator)
+		builder.AddTypeDiscriminatorField(t.TypeDiscriminator, entityName, !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly)
+	}
+
+	if inlineField != "" {
+		builder.addInlineMapToJSON()
+	}
+
+	for _, computed := range t.computedFields[entityName] {
+		t.logf(depth, "- computed field %s", computed.declaration)
+		builder.AddComputedField(computed.declaration, computed.initializer)
+	}
+	if t.CreateInterface || t.DeclarationOnly { // Classes need method bodies
+		for _, signature := range t.methodSignatures[entityName] {
+			t.logf(depth, "- method %s", signature)
+			builder.AddComputedField(signature, "")
+		}
+	}
+
+	if t.CreateFromMethod {
+		t.CreateConstructor = true
+	}
+
+	t.manifest = append(t.manifest, ManifestType{Name: entityName, GoType: typeOf.String(), Fields: builder.manifestFields})
+	t.converters = append(t.converters, converter{entityName: entityName, body: builder.converterBody, convertsValues: builder.convertsValues})
+
+	memoized := false // createFrom uses the cache
+	result += strings.Join(builder.fields, "\n") + "\n"
+	for _, accessor := range builder.accessors {
+		result += "\n" + accessor
+	}
+	if !t.CreateInterface && !t.ReadonlyTypeAlias && t.DeclarationOnly {
+		result += t.convertClassDeclarations(entityName, builder.convertsValues)
+	} else if !t.CreateInterface && !t.ReadonlyTypeAlias {
+		constructorBody := strings.Join(builder.constructorBody, "\n")
+		needsConvertValue := builder.convertsValues
+		if t.CreateFromMethod && t.ExternalCreateFrom {
+			create := fmt.Sprintf("create%s(source)", entityName)
+			if t.FreezeCreateFrom {
+				result += fmt.Sprintf("\n%sstatic %s(source: %s = {}): Readonly<%s> {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+				create = "Object.freeze(" + create + ")"
+			} else {
+				result += fmt.Sprintf("\n%sstatic %s(source: %s = {}): %s {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+			}
+			result += fmt.Sprintf("%sreturn %s;\n", builder.indentation(2), create)
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		} else if t.CreateFromMethod && t.LiteralCreateFrom {
+			literal := "{\n" + strings.Join(builder.createFromMethodBody, "\n") + "\n" + builder.indentation(2) + "} as " + entityName
+			if t.FreezeCreateFrom {
+				result += fmt.Sprintf("\n%sstatic %s(source: %s = {}): Readonly<%s> {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+				literal = "Object.freeze(" + literal + ")"
+			} else {
+				result += fmt.Sprintf("\n%sstatic %s(source: %s = {}): %s {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+			}
+			result += builder.indentation(2) + "if ('string' === typeof source) source = JSON.parse(source);\n"
+			result += t.convertCreateFromSwitch(builder, entityName)
+			result += fmt.Sprintf("%sreturn %s;\n", builder.indentation(2), literal)
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		} else if t.CreateFromMethod && t.FreezeCreateFrom {
+			result += fmt.Sprintf("\n%sstatic %s(source: %s = {}): Readonly<%s> {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+			result += fmt.Sprintf("%sreturn Object.freeze(new %s(source));\n", builder.indentation(2), entityName)
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		} else if t.CreateFromMethod && t.MemoizeCreateFrom {
+			memoized = true
+			cache := entityName + "CreateFromCache"
+			result += fmt.Sprintf("\n%sstatic %s(source: %s = {}) {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName))
+			result += fmt.Sprintf("%sif (!source || 'object' !== typeof source) return new %s(source);\n", builder.indentation(2), entityName)
+			result += fmt.Sprintf("%slet cached = %s.get(source);\n", builder.indentation(2), cache)
+			result += fmt.Sprintf("%sif (!cached) {\n", builder.indentation(2))
+			result += fmt.Sprintf("%scached = new %s(source);\n", builder.indentation(3), entityName)
+			result += fmt.Sprintf("%s%s.set(source, cached);\n", builder.indentation(3), cache)
+			result += fmt.Sprintf("%s}\n", builder.indentation(2))
+			result += fmt.Sprintf("%sreturn cached;\n", builder.indentation(2))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		} else if t.CreateFromMethod {
+			result += fmt.Sprintf("\n%sstatic %s(source: %s = {}) {\n", builder.indentation(1), t.createFromMethodName(), t.sourceType(entityName))
+			result += fmt.Sprintf("%sreturn new %s(source);\n", builder.indentation(2), entityName)
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateFromPartial && t.CreateFromMethod && t.LiteralCreateFrom {
+			result += fmt.Sprintf("\n%sstatic fromPartial(source: Partial<%s> = {}): %s {\n", builder.indentation(1), entityName, entityName)
+			result += fmt.Sprintf("%sreturn {...%s.%s({\n%s\n%s}), ...source};\n", builder.indentation(2), entityName, t.createFromMethodName(), strings.Join(builder.zeroValues, "\n"), builder.indentation(2))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		} else if t.CreateFromPartial && t.CreateConstructor {
+			if len(builder.getterOnly) > 0 { // Object.assign() can't set them
+				return "", fmt.Errorf("%s.fromPartial() can't set the readonly private fields (%s)", entityName, strings.Join(builder.getterOnly, ", "))
+			}
+			result += fmt.Sprintf("\n%sstatic fromPartial(source: Partial<%s> = {}): %s {\n", builder.indentation(1), entityName, entityName)
+			result += fmt.Sprintf("%sreturn Object.assign(%s({\n%s\n%s}), source);\n", builder.indentation(2), t.instanceCreation(entityName), strings.Join(builder.zeroValues, "\n"), builder.indentation(2))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateConstructor && !t.externalClasses() {
+			if strings.Contains(t.SourceType, "__TYPE__") { // The fields of the (i.e. Partial<>) source may be optional, so it's read as any
+				result += fmt.Sprintf("\n%sconstructor(input: %s = {}) {\n", builder.indentation(1), t.sourceType(entityName))
+				if len(bases) > 0 {
+					result += builder.indentation(2) + "super(input);\n"
+				}
+				result += builder.indentation(2) + "let source: any = input;\n"
+			} else {
+				result += fmt.Sprintf("\n%sconstructor(source: %s = {}) {\n", builder.indentation(1), t.sourceType(entityName))
+				if len(bases) > 0 {
+					result += builder.indentation(2) + "super(source);\n"
 				}
 			}
+			result += builder.indentation(2) + "if ('string' === typeof source) source = JSON.parse(source);\n"
+			result += t.convertCreateFromSwitch(builder, entityName)
+			result += constructorBody + "\n"
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateToJSON || builder.privateFields { // Private fields aren't serialized by JSON.stringify()
+			result += fmt.Sprintf("\n%stoJSON(): any {\n", builder.indentation(1))
+			result += fmt.Sprintf("%sreturn {\n", builder.indentation(2))
+			result += strings.Join(builder.toJSONBody, "\n") + "\n"
+			result += fmt.Sprintf("%s};\n", builder.indentation(2))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateToFormData {
+			result += fmt.Sprintf("\n%stoFormData(): FormData {\n", builder.indentation(1))
+			result += fmt.Sprintf("%sconst formData = new FormData();\n", builder.indentation(2))
+			result += fmt.Sprintf("%sconst append = (key: string, value: any) => {\n", builder.indentation(2))
+			result += fmt.Sprintf("%sif (value === undefined || value === null) return;\n", builder.indentation(3))
+			result += fmt.Sprintf("%sformData.append(key, value instanceof Blob ? value : 'object' === typeof value ? JSON.stringify(value) : String(value));\n", builder.indentation(3))
+			result += fmt.Sprintf("%s};\n", builder.indentation(2))
+			if len(builder.formDataBody) > 0 {
+				result += strings.Join(builder.formDataBody, "\n") + "\n"
+			}
+			result += fmt.Sprintf("%sreturn formData;\n", builder.indentation(2))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateClone && t.CreateConstructor {
+			result += fmt.Sprintf("\n%sclone(): %s {\n", builder.indentation(1), entityName)
+			result += fmt.Sprintf("%sreturn %s(JSON.parse(JSON.stringify(this)));\n", builder.indentation(2), t.instanceCreation(entityName))
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if t.CreateSetter {
+			result += fmt.Sprintf("\n%sset<K extends keyof %s>(key: K, value: %s[K]): void {\n", builder.indentation(1), entityName, entityName)
+			result += fmt.Sprintf("%s(this as %s)[key] = value;\n", builder.indentation(2), entityName)
+			result += fmt.Sprintf("%s}\n", builder.indentation(1))
+		}
+		if needsConvertValue && (t.CreateConstructor || t.CreateFromMethod) && !t.externalClasses() {
+			convertValues := tsConvertValuesFunc
+			if memoized || t.abstractClasses() { // Nested objects are memoized too (and abstract ones can't be instantiated)
+				convertValues = strings.ReplaceAll(convertValues, "return new classs(a);", "return classs."+t.createFromMethodName()+"(a);")
+			}
+			result += "\n" + builder.indentCode(convertValues, 1) + "\n"
+		}
+		if needsConvertValue && t.CreateFromMethod && t.LiteralCreateFrom && !t.ExternalCreateFrom {
+			createFromValues := strings.ReplaceAll(tsCreateFromValuesFunc, "classs.createFrom(", "classs."+t.createFromMethodName()+"(")
+			result += "\n" + builder.indentCode(createFromValues, 1) + "\n"
+		}
+	}
+
+	if !t.CreateInterface && !t.ReadonlyTypeAlias && !t.DeclarationOnly && (t.CreateConstructor || t.CreateFromMethod && (t.LiteralCreateFrom || t.ExternalCreateFrom)) {
+		t.addValueDependencies(entityName, builder.valueRefs...) // Used in the constructor or createFrom()
+		t.addUsedNames(entityName, builder.usedNames...)
+	}
+
+	if customCode != nil {
+		code := customCode[entityName]
+		if t.hasCustomCode(code) {
+			result += customCodeBlock(builder.indentation(1), entityName, code)
+		}
+	}
+
+	if t.SkippedFieldsComment && len(skipped) > 0 {
+		if strings.HasSuffix(result, "}\n") { // After a method
+			result += "\n"
+		}
+		result += fmt.Sprintf("%s// skipped: %s\n", builder.indentation(1), strings.Join(skipped, ", "))
+	}
+
+	if t.ReadonlyTypeAlias {
+		result += builder.indentation(0) + "}>;"
+	} else {
+		result += builder.indentation(0) + "}"
+	}
+	if memoized {
+		result += fmt.Sprintf("\n%sconst %sCreateFromCache = new WeakMap<object, %s>();", builder.indentation(0), entityName, entityName)
+	}
+
+	if t.externalClasses() {
+		function := t.convertConverter(converter{entityName: entityName, body: builder.converterBody, instance: true, dontExport: t.DontExport})
+		for _, ref := range builder.valueRefs { // Nested (and base) entities are created with their functions
+			t.addUsedNames(entityName, "create"+ref)
+		}
+		if builder.convertsValues {
+			t.usesConvertValues = true
+			t.addUsedNames(entityName, "convertValues")
+		}
+		result += "\n" + t.indentCode(strings.TrimSuffix(function, "\n"), 0)
+	}
+
+	if t.CreateFieldNames {
+		result += "\n" + t.convertFieldNames(entityName, bases, builder.manifestFields)
+	}
+	if t.CreateComparators {
+		result += t.convertComparator(entityName, bases, builder.manifestFields)
+	}
+
+	t.entityCode[entityName] = result
+	return nested + result, nil
+}
+
+// convertFieldNames creates a const array with the property names of the entity (including the names of the extended
+// base classes).
+func (t *TypeScriptify) convertFieldNames(entityName string, bases []reflect.Type, fields []ManifestField) string {
+	var names []string
+	for _, base := range bases {
+		t.addUsedNames(entityName, t.entityName(base)+"Fields")
+		if t.DeclarationOnly {
+			names = append(names, "...typeof "+t.entityName(base)+"Fields")
+		} else {
+			names = append(names, "..."+t.entityName(base)+"Fields")
+		}
+	}
+	for _, field := range fields {
+		names = append(names, fmt.Sprintf("%q", field.Name))
+	}
+
+	var result string
+	if t.DeclarationOnly {
+		result = fmt.Sprintf("declare const %sFields: readonly [%s];", entityName, strings.Join(names, ", "))
+	} else {
+		result = fmt.Sprintf("const %sFields = [%s] as const;", entityName, strings.Join(names, ", "))
+	}
+	if !t.DontExport {
+		result = "export " + result
+	}
+	return t.indentation(0) + result
+}
+
+// convertComparator returns the `compareFooBy(key)` function, which returns a comparator of the objects by a property.
+// Only number, string and boolean (also enum) properties can be compared, missing values are first.
+func (t *TypeScriptify) convertComparator(entityName string, bases []reflect.Type, fields []ManifestField) string {
+	for _, base := range bases { // Also the inherited properties
+		for _, manifestType := range t.manifest {
+			if manifestType.Name == t.entityName(base) {
+				fields = append(append([]ManifestField{}, manifestType.Fields...), fields...)
+			}
+		}
+	}
+	var keys []string
+	for _, field := range fields {
+		switch field.Kind {
+		case reflect.Bool.String(), reflect.String.String(),
+			reflect.Int.String(), reflect.Int8.String(), reflect.Int16.String(), reflect.Int32.String(), reflect.Int64.String(),
+			reflect.Uint.String(), reflect.Uint8.String(), reflect.Uint16.String(), reflect.Uint32.String(), reflect.Uint64.String(),
+			reflect.Float32.String(), reflect.Float64.String():
+			keys = append(keys, fmt.Sprintf("%q", field.Name))
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+
+	export := ""
+	if !t.DontExport {
+		export = "export "
+	}
+	signature := fmt.Sprintf("function compare%sBy<K extends %s>(key: K): (a: %s, b: %s) => number", entityName, strings.Join(keys, " | "), entityName, entityName)
+	if t.DeclarationOnly {
+		return fmt.Sprintf("\n%s%sdeclare %s;", t.indentation(0), export, signature)
+	}
+	result := fmt.Sprintf("\n%s%s%s {\n", t.indentation(0), export, signature)
+	result += fmt.Sprintf("%sreturn (a: %s, b: %s) => {\n", t.indentation(1), entityName, entityName)
+	result += fmt.Sprintf("%sconst x: any = a[key], y: any = b[key];\n", t.indentation(2))
+	result += fmt.Sprintf("%sif (x === y) return 0;\n", t.indentation(2))
+	result += fmt.Sprintf("%sif (x === undefined || x === null) return -1;\n", t.indentation(2))
+	result += fmt.Sprintf("%sif (y === undefined || y === null) return 1;\n", t.indentation(2))
+	result += fmt.Sprintf("%sreturn x < y ? -1 : 1;\n", t.indentation(2))
+	result += fmt.Sprintf("%s};\n", t.indentation(1))
+	return result + t.indentation(0) + "}"
+}
+
+// AddSumType adds all the variants and creates an union type of them.
+func (t *TypeScriptify) AddSumType(name string, variants []interface{}) *TypeScriptify {
+	return t.AddSumTypeWithDiscriminator(name, "", variants)
+}
+
+// AddSumTypeWithDiscriminator adds all the variants and creates an union type of them. Every variant (without a field
+// with the same JSON name) gets a discriminator field with the type name as literal type.
+func (t *TypeScriptify) AddSumTypeWithDiscriminator(name, discriminator string, variants []interface{}) *TypeScriptify {
+	sum := sumType{name: name, discriminator: discriminator}
+	for _, variant := range variants {
+		if typ, is := variant.(reflect.Type); is {
+			sum.variants = append(sum.variants, typ)
+		} else {
+			sum.variants = append(sum.variants, reflect.TypeOf(variant))
+		}
+	}
+	t.sumTypes = append(t.sumTypes, sum)
+	return t
+}
 
-			builder.AddMapField(jsonFieldName, field)
-		} else if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array { // Slice:
-			if field.Type.Elem().Kind() == reflect.Ptr { //extract ptr type
-				field.Type = field.Type.Elem()
-			}
+// AddUnion adds the implementors of the Golang interface, fields (and slices) of that interface are converted to an
+// union of them. The union type is named as the interface, for unnamed interfaces (`interface{}`) the union is used
+// inline (`Circle | Square`).
+func (t *TypeScriptify) AddUnion(iface reflect.Type, impls []interface{}) *TypeScriptify {
+	return t.AddUnionWithDiscriminator(iface, "", impls)
+}
 
-			arrayDepth := 1
-			for field.Type.Elem().Kind() == reflect.Slice { // Slice of slices:
-				field.Type = field.Type.Elem()
-				arrayDepth++
-			}
+// AddUnionWithDiscriminator is AddUnion with a discriminator field (see AddSumTypeWithDiscriminator), fields (and
+// slices) of the interface are created with the `createXxx()` function, which creates the variant by the
+// discriminator.
+func (t *TypeScriptify) AddUnionWithDiscriminator(iface reflect.Type, discriminator string, impls []interface{}) *TypeScriptify {
+	t.AddSumTypeWithDiscriminator(typeName(iface), discriminator, impls)
+	t.sumTypes[len(t.sumTypes)-1].iface = iface
+	return t
+}
 
-			if field.Type.Elem().Kind() == reflect.Struct { // Slice of structs:
-				t.logf(depth, "- struct slice %s.%s (%s)", typeOf.Name(), field.Name, field.Type.String())
-				typeScriptChunk, err := t.convertType(depth+1, field.Type.Elem(), customCode)
-				if err != nil {
-					return "", err
-				}
-				if typeScriptChunk != "" {
-					result = typeScriptChunk + "\n" + result
-				}
-				builder.AddArrayOfStructsField(jsonFieldName, field, arrayDepth)
-			} else { // Slice of simple fields:
-				t.logf(depth, "- slice field %s.%s", typeOf.Name(), field.Name)
-				err = builder.AddSimpleArrayField(jsonFieldName, field, arrayDepth, fldOpts)
-			}
-		} else { // Simple field:
-			t.logf(depth, "- simple field %s.%s", typeOf.Name(), field.Name)
-			err = builder.AddSimpleField(jsonFieldName, field, fldOpts)
+// AddStringUnion creates an union of string literals. The values can be a slice (the order is preserved) or a map
+// (the keys are used, sorted alphabetically so that the output is always the same).
+func (t *TypeScriptify) AddStringUnion(name string, values interface{}) *TypeScriptify {
+	union := stringUnion{name: name}
+	items := reflect.ValueOf(values)
+	switch items.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < items.Len(); i++ {
+			union.values = append(union.values, fmt.Sprint(items.Index(i).Interface()))
 		}
-		if err != nil {
-			return "", err
+	case reflect.Map:
+		for _, key := range items.MapKeys() {
+			union.values = append(union.values, fmt.Sprint(key.Interface()))
 		}
+		sort.Strings(union.values)
+	default:
+		panic(fmt.Sprintf("Values for %T isn't a slice or map", values))
 	}
+	t.unions = append(t.unions, union)
+	return t
+}
 
-	if t.CreateFromMethod {
-		t.CreateConstructor = true
+// AddComputedField adds a field which doesn't exist in the Golang struct to the entity (with prefix and suffix).
+// The declaration is the TypeScript field declaration (i.e. `fullName: string`), and the initializer is the
+// expression used in the constructor (i.e. `source["name"] + " " + source["surname"]`). If the initializer is
+// empty, the field is only declared.
+func (t *TypeScriptify) AddComputedField(entityName, declaration, initializer string) *TypeScriptify {
+	if t.computedFields == nil {
+		t.computedFields = map[string][]computedField{}
+	}
+	t.computedFields[entityName] = append(t.computedFields[entityName], computedField{declaration: declaration, initializer: initializer})
+	return t
+}
+
+// AddCreateFromSwitch adds a `switch` on the discriminator field of the source to the constructor (and the literal
+// createFrom()) of the entity (with prefix and suffix), i.e. to migrate older versions of the JSON. The cases map the
+// TypeScript literal values (i.e. `1` or `"v2"`, or `default`) to the code run (before the fields are created) if the
+// discriminator has the value. The code can change `source`.
+func (t *TypeScriptify) AddCreateFromSwitch(entityName, discriminator string, cases map[string]string) *TypeScriptify {
+	if t.createFromSwitches == nil {
+		t.createFromSwitches = map[string]createFromSwitch{}
 	}
+	t.createFromSwitches[entityName] = createFromSwitch{discriminator: discriminator, cases: cases}
+	return t
+}
 
-	result += strings.Join(builder.fields, "\n") + "\n"
-	if !t.CreateInterface {
-		constructorBody := strings.Join(builder.constructorBody, "\n")
-		needsConvertValue := strings.Contains(constructorBody, "this.convertValues")
-		if t.CreateFromMethod {
-			result += fmt.Sprintf("\n%sstatic createFrom(source: any = {}) {\n", t.Indent)
-			result += fmt.Sprintf("%s%sreturn new %s(source);\n", t.Indent, t.Indent, entityName)
-			result += fmt.Sprintf("%s}\n", t.Indent)
-		}
-		if t.CreateConstructor {
-			result += fmt.Sprintf("\n%sconstructor(source: any = {}) {\n", t.Indent)
-			result += t.Indent + t.Indent + "if ('string' === typeof source) source = JSON.parse(source);\n"
-			result += constructorBody + "\n"
-			result += fmt.Sprintf("%s}\n", t.Indent)
-		}
-		if needsConvertValue && (t.CreateConstructor || t.CreateFromMethod) {
-			result += "\n" + indentLines(strings.ReplaceAll(tsConvertValuesFunc, "\t", t.Indent), 1) + "\n"
+// convertCreateFromSwitch creates the switch added with AddCreateFromSwitch(), empty if there is none. The cases are
+// sorted, with the default case last.
+func (t *TypeScriptify) convertCreateFromSwitch(builder typeScriptClassBuilder, entityName string) string {
+	sw, found := t.createFromSwitches[entityName]
+	if !found || len(sw.cases) == 0 {
+		return ""
+	}
+	var values []string
+	for value := range sw.cases {
+		if value != "default" {
+			values = append(values, value)
 		}
 	}
+	sort.Strings(values)
+	if _, found := sw.cases["default"]; found {
+		values = append(values, "default")
+	}
 
-	if customCode != nil {
-		code := customCode[entityName]
-		if len(code) != 0 {
-			result += t.Indent + "//[" + entityName + ":]\n" + code + "\n\n" + t.Indent + "//[end]\n"
+	result := fmt.Sprintf("%sswitch (source[%q]) {\n", builder.indentation(2), sw.discriminator)
+	for _, value := range values {
+		if value == "default" {
+			result += builder.indentation(3) + "default:\n"
+		} else {
+			result += fmt.Sprintf("%scase %s:\n", builder.indentation(3), value)
 		}
+		for _, line := range strings.Split(strings.TrimSpace(sw.cases[value]), "\n") {
+			result += builder.indentation(4) + strings.TrimRight(line, " \t\r") + "\n"
+		}
+		result += builder.indentation(4) + "break;\n"
 	}
+	return result + builder.indentation(2) + "}\n"
+}
 
-	result += "}"
+// AddMethodSignature adds a method signature (i.e. `getName(): string`) to the interface (with prefix and suffix).
+// Golang methods can't be converted, so this is used for methods (i.e. of embedded types) which are implemented in
+// TypeScript. The signatures are added to interfaces (also the ones created for embedded Golang interfaces) and
+// declarations, but not to classes.
+func (t *TypeScriptify) AddMethodSignature(entityName, signature string) *TypeScriptify {
+	if t.methodSignatures == nil {
+		t.methodSignatures = map[string][]string{}
+	}
+	t.methodSignatures[entityName] = append(t.methodSignatures[entityName], signature)
+	return t
+}
 
-	return result, nil
+// convertClassDeclarations creates method declarations (without bodies) for declaration (.d.ts) files.
+func (t *TypeScriptify) convertClassDeclarations(entityName string, needsConvertValue bool) string {
+	result := ""
+	if t.CreateFromMethod && t.FreezeCreateFrom {
+		result += fmt.Sprintf("\n%sstatic %s(source?: %s): Readonly<%s>;\n", t.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+	} else if t.CreateFromMethod {
+		result += fmt.Sprintf("\n%sstatic %s(source?: %s): %s;\n", t.indentation(1), t.createFromMethodName(), t.sourceType(entityName), entityName)
+	}
+	if t.CreateConstructor || t.CreateFromMethod {
+		result += fmt.Sprintf("\n%sconstructor(source?: %s);\n", t.indentation(1), t.sourceType(entityName))
+	}
+	if t.CreateFromPartial && (t.CreateConstructor || t.CreateFromMethod) {
+		result += fmt.Sprintf("\n%sstatic fromPartial(source?: Partial<%s>): %s;\n", t.indentation(1), entityName, entityName)
+	}
+	if t.CreateToJSON {
+		result += fmt.Sprintf("\n%stoJSON(): any;\n", t.indentation(1))
+	}
+	if t.CreateToFormData {
+		result += fmt.Sprintf("\n%stoFormData(): FormData;\n", t.indentation(1))
+	}
+	if t.CreateClone && t.CreateConstructor { // As in the class
+		result += fmt.Sprintf("\n%sclone(): %s;\n", t.indentation(1), entityName)
+	}
+	if t.CreateSetter {
+		result += fmt.Sprintf("\n%sset<K extends keyof %s>(key: K, value: %s[K]): void;\n", t.indentation(1), entityName, entityName)
+	}
+	if t.CreateConstructor || t.CreateFromMethod {
+		if needsConvertValue {
+			result += fmt.Sprintf("\n%sconvertValues(a: any, classs: any, asMap?: boolean): any;\n", t.indentation(1))
+		}
+	}
+	return result
+}
+
+// SetCustomCode sets custom code for the entity (with prefix and suffix) which will be placed between the
+// `//[Name:]` and `//[end]` markers. Code set here overrides custom code loaded from the target file.
+func (t *TypeScriptify) SetCustomCode(entityName, code string) *TypeScriptify {
+	if t.customCode == nil {
+		t.customCode = map[string]string{}
+	}
+	t.customCode[entityName] = code
+	return t
 }
 
-func (t *TypeScriptify) AddImport(i string) {
+// AddImport adds a custom import (or any other code, i.e. a helper function used in ts_transform) at the start of the
+// generated code. Every import is added only once, and also to every file written by ConvertToFiles() and ConvertToDir().
+func (t *TypeScriptify) AddImport(i string) *TypeScriptify {
 	for _, cimport := range t.customImports {
 		if cimport == i {
-			return
+			return t
 		}
 	}
 
 	t.customImports = append(t.customImports, i)
+	return t
 }
 
 type typeScriptClassBuilder struct {
 	types                map[reflect.Kind]string
 	indent               string
+	depth                int // The depth of the class (the members are one level deeper)
 	fields               []string
 	createFromMethodBody []string
+	converterBody        []string // The object literal lines of the createXxx() function (see converter)
 	constructorBody      []string
 	prefix, suffix       string
+	stripSuffix          string
+	quoteNames           bool
+	explicitUndefined    bool
+	trailingCommas       bool
+	nameTransform        func(string) string
+	enums                map[reflect.Type][]enumElement
+	anonymousNames       map[reflect.Type]string
+	readonlyFields       bool
+	privateFields        bool
+	accessors            []string // Getters and setters of the private fields
+	getterOnly           []string // Readonly private fields (without setters)
+	readonlyContainers   bool
+	arrayTuples          bool
+	toJSONBody           []string
+	formDataBody         []string
+	nullValue            string
+	numberCoercion       string
+	optionalChaining     bool
+	definiteFields       bool // The fields are assigned outside of the class (by createXxx()), so they're declared with `!`
+	rawMessageType       string
+	skipMissing          bool         // The field currently added is initialized only if in the source
+	nullable             bool         // The field currently added is nullable
+	nullish              bool         // The field currently added is also `| undefined` if nullable and optional
+	optional             bool         // The field currently added is optional
+	kind                 reflect.Kind // The kind of the field currently added
+	tag                  string       // The tag of the field currently added, if set it's added as a comment
+	tsName               string       // The ts_name of the field currently added
+	doc                  string       // The ts_doc of the field currently added
+	transformBack        string       // The ts_transform_back of the field currently added (used in toJSON())
+	defaultValue         string       // The ts_default of the field currently added (only in classes)
+	keyType              string       // The ts_key_type of the (map) field currently added
+	arrayLengths         []int        // The array lengths of the field currently added (only with arrayTuples)
+	nullableElements     bool         // The elements of the (slice) field currently added are nullable
+	readonly             bool         // The field currently added is readonly
+	see                  string       // The type referenced by the field currently added
+	decorators           []string     // The decorators of the field currently added
+	fieldGroup           string       // The embedded struct from which the last added field is promoted
+	seeComments          bool
+	zeroValue            string // The zero value of the field currently added (empty if unknown)
+	zeroValues           []string
+	manifestFields       []ManifestField
+	containerTypes       map[reflect.Type]reflect.Type // Custom containers, converted as their shapes
+	valueRefs            []string                      // Entities created (i.e. with convertValues()) in the constructor and createFrom()
+	usedNames            []string                      // Functions of other entities (sum type dispatchers) used in the constructor and createFrom()
+	convertsValues       bool                          // The structs of some fields are created with convertValues()
+}
+
+// valuesConversion returns the code creating the struct from the value (or the structs in the slices and maps of the
+// value, asMap if the value is a map of them).
+type valuesConversion func(value, structName string, asMap bool) string
+
+// constructorConversion creates the structs with the convertValues() method of the class.
+func (t *typeScriptClassBuilder) constructorConversion(value, structName string, asMap bool) string {
+	t.convertsValues = true
+	return convertValuesCall("this.convertValues", value, structName, asMap)
+}
+
+// createFromConversion creates the structs with the (static) createFromValues() method of the class.
+func (t *typeScriptClassBuilder) createFromConversion(value, structName string, asMap bool) string {
+	t.convertsValues = true
+	return convertValuesCall("this.createFromValues", value, structName, asMap)
+}
+
+// converterConversion creates the structs with the convertValues() function and the createXxx() functions.
+func (t *typeScriptClassBuilder) converterConversion(value, structName string, asMap bool) string {
+	t.convertsValues = true
+	return convertValuesCall("convertValues", value, "create"+structName, asMap)
+}
+
+// convertValuesCall returns the call of the convertValues() function (or method) converting the value with create.
+func convertValuesCall(function, value, create string, asMap bool) string {
+	if asMap {
+		return fmt.Sprintf("%s(%s, %s, true)", function, value, create)
+	}
+	return fmt.Sprintf("%s(%s, %s)", function, value, create)
+}
+
+// converterIndentation returns the indentation of the object literal lines in the createXxx() functions.
+func (t *typeScriptClassBuilder) converterIndentation() string {
+	return strings.Repeat(t.indent, 2)
+}
+
+// indentation returns the indentation of code the given number of levels deeper than the class.
+func (t *typeScriptClassBuilder) indentation(level int) string {
+	return strings.Repeat(t.indent, t.depth+level)
+}
+
+// indentCode indents the (tab indented) code the given number of levels deeper than the class.
+func (t *typeScriptClassBuilder) indentCode(code string, level int) string {
+	lines := strings.Split(strings.ReplaceAll(code, "\t", t.indent), "\n")
+	for n := range lines {
+		lines[n] = t.indentation(level) + lines[n]
+	}
+	return strings.Join(lines, "\n")
 }
 
-func (t *typeScriptClassBuilder) AddSimpleArrayField(fieldName string, field reflect.StructField, arrayDepth int, opts TypeOptions) error {
-	fieldType, kind := field.Type.Elem().Name(), field.Type.Elem().Kind()
+func (t *typeScriptClassBuilder) AddSimpleArrayField(fieldName string, elemType reflect.Type, arrayDepth int, opts TypeOptions) error {
+	fieldType, kind := elemType.Name(), elemType.Kind()
 	typeScriptType := t.types[kind]
+	if isByteSlice(elemType) {
+		typeScriptType = "string"
+	}
 
 	if len(fieldName) > 0 {
 		strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
 		if len(opts.TSType) > 0 {
 			t.addField(fieldName, opts.TSType)
-			t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("source[\"%s\"]", strippedFieldName))
+			t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
 			return nil
 		} else if len(typeScriptType) > 0 {
-			t.addField(fieldName, fmt.Sprint(typeScriptType, strings.Repeat("[]", arrayDepth)))
-			t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("source[\"%s\"]", strippedFieldName))
+			t.addField(fieldName, t.arrayType(typeScriptType, arrayDepth))
+			if t.coerceNumbers(elemType) {
+				t.addInitializerFieldLine(strippedFieldName, t.numberCoercionTransform(arrayDepth, false, t.sourceAccess(strippedFieldName)))
+			} else {
+				t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
+			}
 			return nil
 		}
 	}
@@ -735,6 +3356,102 @@ func (t *typeScriptClassBuilder) AddSimpleArrayField(fieldName string, field ref
 	return fmt.Errorf("cannot find type for %s (%s/%s)", kind.String(), fieldName, fieldType)
 }
 
+func (t *typeScriptClassBuilder) AddMapField(fieldName string, field reflect.StructField) error {
+	typeScriptType, err := t.typeScriptType(field.Type)
+	if err != nil {
+		return fmt.Errorf("cannot find type for %s: %s", fieldName, err.Error())
+	}
+	if t.keyType != "" {
+		if typeScriptType, err = t.keyedMapType(field.Type, t.keyType); err != nil {
+			return fmt.Errorf("cannot find type for %s: %s", fieldName, err.Error())
+		}
+	}
+	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
+
+	valueType, _ := containedStruct(field.Type.Elem())
+	if valueType != nil {
+		t.see = t.entityName(valueType)
+		t.valueRefs = append(t.valueRefs, t.see)
+		fieldName = t.missingStructFieldName(fieldName)
+	}
+	t.addField(fieldName, typeScriptType)
+	if valueType != nil {
+		t.addStructInitializerFieldLine(strippedFieldName, t.mapValuesInitializer(strippedFieldName, field.Type, valueType))
+	} else if t.coerceNumbers(field.Type.Elem()) {
+		val := t.sourceAccess(strippedFieldName)
+		coerced := strings.ReplaceAll(t.numberCoercionTransform(0, false, "__VALUE__[k]"), "__VALUE__", val)
+		t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("%s ? Object.keys(%s).reduce((m: any, k: string) => { m[k] = %s; return m; }, {}) : %s", val, val, coerced, val))
+	} else {
+		t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
+	}
+	return nil
+}
+
+// mapValuesInitializer returns the initializer creating the structs contained in the (field) type with maps.
+func (t *typeScriptClassBuilder) mapValuesInitializer(fld string, typ, valueType reflect.Type) func(valuesConversion) string {
+	return func(convert valuesConversion) string {
+		return t.valuesTransform(convert, typ, t.sourceAccess(fld), 1, t.entityName(valueType))
+	}
+}
+
+// valuesTransform returns the code rebuilding the (arbitrarily nested) slices and maps of the value, the contained
+// structs are created with convertValues(). It handles only one level of maps, so the outer maps are rebuilt here.
+func (t *typeScriptClassBuilder) valuesTransform(convert valuesConversion, typ reflect.Type, value string, level int, structName string) string {
+	if maps := countMaps(typ); maps <= 1 {
+		return convert(value, structName, maps == 1)
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return t.valuesTransform(convert, typ.Elem(), value, level, structName)
+	case reflect.Slice, reflect.Array:
+		v := fmt.Sprintf("v%d", level)
+		return fmt.Sprintf("%s ? %s.map((%s: any) => %s) : %s", value, value, v, t.valuesTransform(convert, typ.Elem(), v, level+1, structName), value)
+	case reflect.Map:
+		m, k := fmt.Sprintf("m%d", level), fmt.Sprintf("k%d", level)
+		elem := t.valuesTransform(convert, typ.Elem(), value+"["+k+"]", level+1, structName)
+		return fmt.Sprintf("%s ? Object.keys(%s).reduce((%s: any, %s: string) => { %s[%s] = %s; return %s; }, {}) : %s", value, value, m, k, m, k, elem, m, value)
+	}
+	return value
+}
+
+// coerceNumbers checks if values of the type are reconstructed with the NumberCoercion function.
+func (t *typeScriptClassBuilder) coerceNumbers(typ reflect.Type) bool {
+	if _, isEnum := t.enums[typ]; isEnum || t.numberCoercion == "" {
+		return false
+	}
+	return t.types[typ.Kind()] == "number"
+}
+
+// numberCoercionTransform wraps the value (or the elements of nested arrays) with the NumberCoercion function.
+func (t *typeScriptClassBuilder) numberCoercionTransform(arrayDepth int, guard bool, val string) string {
+	transform := elementsTransform(arrayDepth, guard, t.numberCoercion+"(%s)")
+	return strings.ReplaceAll(transform, "__VALUE__", val)
+}
+
+func (t *typeScriptClassBuilder) AddArrayOfMapsField(fieldName string, elemType reflect.Type, arrayDepth int) error {
+	typeScriptType, err := t.typeScriptType(elemType)
+	if err != nil {
+		return fmt.Errorf("cannot find type for %s: %s", fieldName, err.Error())
+	}
+	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
+
+	if valueType, _ := containedStruct(elemType); valueType != nil {
+		t.see = t.entityName(valueType)
+		t.valueRefs = append(t.valueRefs, t.see)
+	}
+	t.addField(fieldName, t.arrayType(typeScriptType, arrayDepth))
+	if valueType, _ := containedStruct(elemType); valueType != nil {
+		fieldType := elemType
+		for i := 0; i < arrayDepth; i++ {
+			fieldType = reflect.SliceOf(fieldType)
+		}
+		t.addConvertedFieldLine(strippedFieldName, t.mapValuesInitializer(strippedFieldName, fieldType, valueType))
+	} else {
+		t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
+	}
+	return nil
+}
+
 func (t *typeScriptClassBuilder) AddSimpleField(fieldName string, field reflect.StructField, opts TypeOptions) error {
 	fieldType, kind := field.Type.Name(), field.Type.Kind()
 
@@ -746,11 +3463,14 @@ func (t *typeScriptClassBuilder) AddSimpleField(fieldName string, field reflect.
 	if len(typeScriptType) > 0 && len(fieldName) > 0 {
 		strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
 		t.addField(fieldName, typeScriptType)
-		if opts.TSTransform == "" {
-			t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("source[\"%s\"]", strippedFieldName))
+		if opts.TSTransform == "" && opts.TSType == "" && t.coerceNumbers(field.Type) {
+			t.addInitializerFieldLine(strippedFieldName, t.numberCoercionTransform(0, t.optional, t.sourceAccess(strippedFieldName)))
+		} else if opts.TSTransform == "" {
+			t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
 		} else {
-			val := fmt.Sprintf(`source["%s"]`, strippedFieldName)
+			val := t.sourceAccess(strippedFieldName)
 			expression := strings.Replace(opts.TSTransform, "__VALUE__", val, -1)
+			expression = strings.Replace(expression, "__SOURCE__", "source", -1)
 			t.addInitializerFieldLine(strippedFieldName, expression)
 		}
 		return nil
@@ -759,32 +3479,447 @@ func (t *typeScriptClassBuilder) AddSimpleField(fieldName string, field reflect.
 	return fmt.Errorf("cannot find type for %s (%s/%s)", kind.String(), fieldName, fieldType)
 }
 
+// AddFuncField adds an optional field with a function type. Functions can't be serialized, so the field isn't
+// initialized from the source.
+func (t *typeScriptClassBuilder) AddFuncField(fieldName, fieldType string) {
+	if !strings.HasSuffix(fieldName, "?") {
+		fieldName += "?"
+	}
+	t.addField(fieldName, fieldType)
+}
+
 func (t *typeScriptClassBuilder) AddEnumField(fieldName string, field reflect.StructField) {
-	fieldType := field.Type.Name()
-	t.addField(fieldName, t.prefix+fieldType+t.suffix)
+	fieldType := t.entityName(field.Type)
+	t.see = fieldType
+	t.addField(fieldName, fieldType)
 	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
-	t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("source[\"%s\"]", strippedFieldName))
+	t.addInitializerFieldLine(strippedFieldName, t.sourceAccess(strippedFieldName))
 }
 
-func (t *typeScriptClassBuilder) AddStructField(fieldName string, field reflect.StructField) {
-	fieldType := field.Type.Name()
+func (t *typeScriptClassBuilder) AddStructField(fieldName string, field reflect.StructField, instantiateMissing bool) {
+	fieldType := t.entityName(field.Type)
 	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
-	t.addField(fieldName, t.prefix+fieldType+t.suffix)
-	t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("this.convertValues(source[\"%s\"], %s)", strippedFieldName, t.prefix+fieldType+t.suffix))
+	t.see = fieldType
+	t.valueRefs = append(t.valueRefs, fieldType)
+	if instantiateMissing {
+		t.addField(fieldName, fieldType)
+		t.addConvertedFieldLine(strippedFieldName, func(convert valuesConversion) string {
+			return convert(t.sourceAccess(strippedFieldName)+" || {}", fieldType, false)
+		})
+	} else {
+		t.addField(t.missingStructFieldName(fieldName), fieldType)
+		t.addStructInitializerFieldLine(strippedFieldName, func(convert valuesConversion) string {
+			return convert(t.sourceAccess(strippedFieldName), fieldType, false)
+		})
+	}
+}
+
+// AddInlineMapField adds a (ts_inline) map field as an index signature of the class. The keys of the source which
+// aren't JSON names of other fields (knownKeys) are copied to the object. TypeScript requires all the properties (and
+// methods) of the class to match the index signature, so only `any` (or `unknown`) values are allowed.
+func (t *typeScriptClassBuilder) AddInlineMapField(field reflect.StructField, knownKeys []string) error {
+	if field.Type.Kind() != reflect.Map || field.Type.Key().Kind() != reflect.String {
+		return fmt.Errorf("ts_inline field %s must be a map with string keys", field.Name)
+	}
+	value, err := t.typeScriptType(field.Type.Elem())
+	if err != nil {
+		return fmt.Errorf("cannot find type for %s: %s", field.Name, err.Error())
+	}
+	if value != "any" && value != "unknown" {
+		return fmt.Errorf("ts_inline field %s must be a map of interface{} values (the other properties can't match the %s index signature)", field.Name, value)
+	}
+	t.fields = append(t.fields, fmt.Sprintf("%s[key: string]: %s;", t.indentation(1), value))
+
+	t.constructorBody = append(t.constructorBody, fmt.Sprintf("%sfor (const key of Object.keys(source)) if (!%s.includes(key)) this[key] = source[key];", t.indentation(2), jsArray(knownKeys)))
+	spread := fmt.Sprintf("...Object.keys(source).filter(key => !%s.includes(key)).reduce((m: any, key: string) => { m[key] = source[key]; return m; }, {}),", jsArray(knownKeys))
+	t.createFromMethodBody = append(t.createFromMethodBody, t.indentation(3)+spread)
+	t.converterBody = append(t.converterBody, t.converterIndentation()+spread)
+	return nil
+}
+
+// addInlineMapToJSON adds the properties which aren't fields (i.e. the keys of the ts_inline map) to toJSON(), must be
+// called after all fields are added.
+func (t *typeScriptClassBuilder) addInlineMapToJSON() {
+	properties := make([]string, 0, len(t.manifestFields))
+	for _, field := range t.manifestFields {
+		properties = append(properties, field.Name)
+	}
+	t.toJSONBody = append(t.toJSONBody, fmt.Sprintf("%s...Object.keys(this).filter(key => !%s.includes(key)).reduce((m: any, key: string) => { m[key] = this[key]; return m; }, {}),", t.indentation(3), jsArray(properties)))
 }
 
-func (t *typeScriptClassBuilder) AddArrayOfStructsField(fieldName string, field reflect.StructField, arrayDepth int) {
-	fieldType := field.Type.Elem().Name()
+func (t *typeScriptClassBuilder) AddArrayOfStructsField(fieldName string, elemType reflect.Type, arrayDepth int) {
+	fieldType := t.entityName(elemType)
 	strippedFieldName := strings.ReplaceAll(fieldName, "?", "")
-	t.addField(fieldName, fmt.Sprint(t.prefix+fieldType+t.suffix, strings.Repeat("[]", arrayDepth)))
-	t.addInitializerFieldLine(strippedFieldName, fmt.Sprintf("this.convertValues(source[\"%s\"], %s)", strippedFieldName, t.prefix+fieldType+t.suffix))
+	t.see = fieldType
+	t.valueRefs = append(t.valueRefs, fieldType)
+	t.addField(t.missingStructFieldName(fieldName), t.arrayType(fieldType, arrayDepth))
+	t.addStructInitializerFieldLine(strippedFieldName, func(convert valuesConversion) string {
+		return convert(t.sourceAccess(strippedFieldName), fieldType, false)
+	})
+}
+
+// arrayType returns the TypeScript type of an (arrayDepth dimensional) array of elem in the field currently added, fixed
+// size arrays are tuples if arrayTuples is set, and the elements are nullable if nullableElements is set.
+func (t *typeScriptClassBuilder) arrayType(elem string, arrayDepth int) string {
+	tuple := len(t.arrayLengths) == arrayDepth && arrayDepth > 0 && t.arrayLengths[arrayDepth-1] > 0
+	if t.nullableElements {
+		elem += " | null"
+		if !t.readonlyContainers && !tuple {
+			elem = "(" + elem + ")"
+		}
+	}
+	if len(t.arrayLengths) != arrayDepth {
+		return arrayType(elem, arrayDepth, t.readonlyContainers)
+	}
+	for n := arrayDepth - 1; n >= 0; n-- {
+		if t.arrayLengths[n] > 0 {
+			elem = tupleType(elem, t.arrayLengths[n], t.readonlyContainers)
+		} else {
+			elem = arrayType(elem, 1, t.readonlyContainers)
+		}
+	}
+	return elem
+}
+
+func (t *typeScriptClassBuilder) entityName(typeOf reflect.Type) string {
+	return t.prefix + stripTypeNameSuffix(structName(typeOf, t.anonymousNames), t.stripSuffix) + t.suffix
+}
+
+// typeScriptType resolves the TypeScript type for (possibly nested) pointers, slices, arrays and maps.
+func (t *typeScriptClassBuilder) typeScriptType(typ reflect.Type) (string, error) {
+	if shape, found := t.containerTypes[typ]; found {
+		return t.typeScriptType(shape)
+	}
+	if _, isEnum := t.enums[typ]; isEnum {
+		return t.entityName(typ), nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return t.typeScriptType(typ.Elem())
+	case reflect.Slice, reflect.Array:
+		if typ == rawMessageType {
+			return t.rawMessageType, nil
+		}
+		if isByteSlice(typ) {
+			return "string", nil
+		}
+		elem, err := t.typeScriptType(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		if typ.Kind() == reflect.Array && t.arrayTuples {
+			return tupleType(elem, typ.Len(), t.readonlyContainers), nil
+		}
+		return arrayType(elem, 1, t.readonlyContainers), nil
+	case reflect.Map:
+		key, err := t.mapKeyType(typ.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := t.typeScriptType(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		mapType := fmt.Sprintf("{[key: %s]: %s}", key, value)
+		if _, isEnum := t.enums[typ.Key()]; isEnum { // Index signatures can't use enums
+			mapType = fmt.Sprintf("Partial<Record<%s, %s>>", key, value)
+		} else if t.trailingCommas {
+			mapType = fmt.Sprintf("{[key: %s]: %s,}", key, value)
+		}
+		if t.readonlyContainers {
+			mapType = "Readonly<" + mapType + ">"
+		}
+		return mapType, nil
+	case reflect.Struct:
+		return t.entityName(typ), nil
+	}
+	if typeScriptType, found := t.types[typ.Kind()]; found {
+		return typeScriptType, nil
+	}
+	return "", fmt.Errorf("cannot find type for %s (%s)", typ.Kind().String(), typ.String())
+}
+
+// recordType converts a map to a `Record<K, V>` type.
+func (t *typeScriptClassBuilder) recordType(typ reflect.Type) (string, error) {
+	key, err := t.mapKeyType(typ.Key())
+	if err != nil {
+		return "", err
+	}
+	value, err := t.typeScriptType(typ.Elem())
+	if err != nil {
+		return "", err
+	}
+	recordType := fmt.Sprintf("Record<%s, %s>", key, value)
+	if _, isEnum := t.enums[typ.Key()]; isEnum {
+		recordType = "Partial<" + recordType + ">"
+	}
+	if t.readonlyContainers {
+		recordType = "Readonly<" + recordType + ">"
+	}
+	return recordType, nil
+}
+
+// keyedMapType converts a map to a mapped type with the (ts_key_type) keys, i.e. `{[key in Status]?: Foo}`. Not all the
+// keys must be present, so the properties are optional.
+func (t *typeScriptClassBuilder) keyedMapType(typ reflect.Type, keyType string) (string, error) {
+	value, err := t.typeScriptType(typ.Elem())
+	if err != nil {
+		return "", err
+	}
+	mapType := fmt.Sprintf("{[key in %s]?: %s}", keyType, value)
+	if t.readonlyContainers {
+		mapType = "Readonly<" + mapType + ">"
+	}
+	return mapType, nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// mapKeyType resolves the TypeScript type of map keys. As in encoding/json, the keys must be strings, integers or
+// implement encoding.TextMarshaler.
+func (t *typeScriptClassBuilder) mapKeyType(typ reflect.Type) (string, error) {
+	if _, isEnum := t.enums[typ]; isEnum {
+		return t.entityName(typ), nil
+	}
+	if typ.Kind() == reflect.String {
+		return "string", nil
+	}
+	if typ.Implements(textMarshalerType) { // Map keys aren't addressable, so MarshalText() with a pointer receiver isn't used
+		return "string", nil
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "number", nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s (keys must be strings, integers or encoding.TextMarshaler)", typ.String())
+}
+
+func (t *typeScriptClassBuilder) AddDiscriminatorField(fieldName, value string, initialize bool) {
+	if initialize {
+		t.fields = append(t.fields, fmt.Sprintf("%s%s: %q = %q;", t.indentation(1), fieldName, value, value))
+	} else {
+		t.fields = append(t.fields, fmt.Sprintf("%s%s: %q;", t.indentation(1), fieldName, value))
+	}
+}
+
+// AddTypeDiscriminatorField adds the readonly field with the type name, which is also set by the (literal) createFrom.
+func (t *typeScriptClassBuilder) AddTypeDiscriminatorField(fieldName, value string, initialize bool) {
+	t.AddDiscriminatorField("readonly "+propertyKey(fieldName), value, initialize)
+	property := fmt.Sprintf("%s: %q,", propertyKey(fieldName), value)
+	t.createFromMethodBody = append(t.createFromMethodBody, t.indentation(3)+property)
+	t.converterBody = append(t.converterBody, t.converterIndentation()+property)
+}
+
+func (t *typeScriptClassBuilder) AddComputedField(declaration, initializer string) {
+	t.fields = append(t.fields, fmt.Sprint(t.indentation(1), strings.TrimSuffix(strings.TrimSpace(declaration), ";"), ";"))
+	if initializer != "" {
+		fld := strings.TrimSpace(strings.SplitN(declaration, ":", 2)[0])
+		fld = strings.TrimSuffix(fld, "?")
+		t.constructorBody = append(t.constructorBody, fmt.Sprint(t.indentation(2), "this.", fld, " = ", initializer, ";"))
+		t.createFromMethodBody = append(t.createFromMethodBody, fmt.Sprint(t.indentation(3), fld, ": ", initializer, ","))
+		t.converterBody = append(t.converterBody, fmt.Sprint(t.converterIndentation(), fld, ": ", initializer, ","))
+	}
+}
+
+// propertyName returns the TypeScript property name for the JSON field name, tsName is the name of the current struct
+// field (see TypeScriptify.propertyName()).
+func (t *typeScriptClassBuilder) propertyName(jsonFieldName string) string {
+	if t.tsName != "" {
+		return t.tsName
+	}
+	if t.nameTransform == nil {
+		return jsonFieldName
+	}
+	return t.nameTransform(jsonFieldName)
+}
+
+// startFieldGroup separates the groups of fields promoted from embedded structs with a blank line and a comment.
+func (t *typeScriptClassBuilder) startFieldGroup(group string) {
+	if group == t.fieldGroup {
+		return
+	}
+	if len(t.fields) > 0 {
+		t.fields = append(t.fields, "")
+	}
+	if group != "" {
+		t.fields = append(t.fields, t.indentation(1)+"// Promoted from "+group)
+	}
+	t.fieldGroup = group
+}
+
+// missingStructFieldName changes the field (declaration) for the NullValue used for missing nested structs.
+func (t *typeScriptClassBuilder) missingStructFieldName(fieldName string) string {
+	switch t.nullValue {
+	case NullValueNull:
+		t.nullable = true
+	case NullValueUndefined, NullValueSkip:
+		if !strings.HasSuffix(fieldName, "?") {
+			fieldName += "?"
+		}
+	}
+	return fieldName
+}
+
+// addStructInitializerFieldLine adds the initializer of a field with nested structs, using the NullValue for missing
+// values.
+func (t *typeScriptClassBuilder) addStructInitializerFieldLine(fld string, initializer func(valuesConversion) string) {
+	switch t.nullValue {
+	case NullValueNull, NullValueUndefined:
+		converted := initializer
+		initializer = func(convert valuesConversion) string {
+			return fmt.Sprintf("%s ? %s : %s", t.sourceAccess(fld), converted(convert), t.nullValue)
+		}
+	case NullValueSkip:
+		t.skipMissing = true
+	}
+	t.addConvertedFieldLine(fld, initializer)
 }
 
 func (t *typeScriptClassBuilder) addInitializerFieldLine(fld, initializer string) {
-	t.createFromMethodBody = append(t.createFromMethodBody, fmt.Sprint(t.indent, t.indent, "result.", fld, " = ", initializer, ";"))
-	t.constructorBody = append(t.constructorBody, fmt.Sprint(t.indent, t.indent, "this.", fld, " = ", initializer, ";"))
+	t.addConvertedFieldLine(fld, func(valuesConversion) string { return initializer })
+}
+
+// addConvertedFieldLine adds the field initializer, which creates the nested structs with the conversion of the
+// constructor, the createFrom() method or the createXxx() function.
+func (t *typeScriptClassBuilder) addConvertedFieldLine(fld string, initializer func(valuesConversion) string) {
+	property := t.propertyName(fld)
+	constructorInitializer := t.initializerWithDefaults(fld, initializer(t.constructorConversion))
+	createFromInitializer := t.initializerWithDefaults(fld, initializer(t.createFromConversion))
+	converterInitializer := t.initializerWithDefaults(fld, initializer(t.converterConversion))
+	var constructorLine string
+	if t.skipMissing {
+		constructorLine = fmt.Sprintf("%sif (%s) %s = %s;", t.indentation(2), t.sourceAccess(fld), t.thisAccess(property), constructorInitializer)
+	} else {
+		constructorLine = fmt.Sprint(t.indentation(2), t.thisAccess(property), " = ", constructorInitializer, ";")
+	}
+	t.createFromMethodBody = append(t.createFromMethodBody, t.indentation(3)+t.literalProperty(fld, property, createFromInitializer))
+	t.converterBody = append(t.converterBody, t.converterIndentation()+t.literalProperty(fld, property, converterInitializer))
+	t.constructorBody = append(t.constructorBody, constructorLine)
+	t.skipMissing = false
+	value := propertyAccess("this", property)
+	if t.transformBack != "" {
+		value = strings.ReplaceAll(t.transformBack, "__VALUE__", value)
+	}
+	t.toJSONBody = append(t.toJSONBody, fmt.Sprintf("%s%q: %s,", t.indentation(3), fld, value))
+	t.formDataBody = append(t.formDataBody, fmt.Sprintf("%sappend(%q, %s);", t.indentation(2), fld, value))
+	if t.zeroValue != "" && !t.optional {
+		t.zeroValues = append(t.zeroValues, fmt.Sprintf("%s%q: %s,", t.indentation(3), fld, t.zeroValue))
+	}
+}
+
+// initializerWithDefaults wraps the initializer of the field with its ts_default (and explicit undefined).
+func (t *typeScriptClassBuilder) initializerWithDefaults(fld, initializer string) string {
+	if t.defaultValue != "" {
+		if strings.ContainsAny(initializer, "|&?") { // `??` can't be mixed with `||` and `&&` without parentheses
+			initializer = "(" + initializer + ")"
+		}
+		initializer = fmt.Sprintf("%s ?? %s", initializer, t.defaultValue)
+	}
+	if t.explicitUndefined && t.optional {
+		initializer = fmt.Sprintf("%s !== undefined ? %s : undefined", t.sourceAccess(fld), initializer)
+	}
+	return initializer
+}
+
+// literalProperty returns the property of the object literal created in createFrom() (or createXxx()), spread only
+// if in the source when the field is skipped if missing.
+func (t *typeScriptClassBuilder) literalProperty(fld, property, initializer string) string {
+	if t.skipMissing {
+		return fmt.Sprintf("...(%s ? {%s: %s} : {}),", t.sourceAccess(fld), propertyKey(property), initializer)
+	}
+	return fmt.Sprint(propertyKey(property), ": ", initializer, ",")
+}
+
+// sourceAccess returns the expression reading the JSON field from the source (with optional chaining, if set).
+func (t *typeScriptClassBuilder) sourceAccess(fld string) string {
+	if t.optionalChaining {
+		return fmt.Sprintf("source?.[\"%s\"]", fld)
+	}
+	return fmt.Sprintf("source[\"%s\"]", fld)
+}
+
+// isPrivate checks if the property is a private (`#name`) field, only identifiers can be private.
+func (t *typeScriptClassBuilder) isPrivate(property string) bool {
+	return t.privateFields && !t.quoteNames && isIdentifier(property)
+}
+
+// thisAccess returns the access expression of the property in class methods.
+func (t *typeScriptClassBuilder) thisAccess(property string) string {
+	if t.isPrivate(property) {
+		return "this.#" + property
+	}
+	return propertyAccess("this", property)
+}
+
+// addAccessors adds the getter (and setter, if not readonly) of the private field currently added.
+func (t *typeScriptClassBuilder) addAccessors(property, fldType string, optional bool) {
+	if optional && !strings.HasSuffix(fldType, " | undefined") {
+		fldType += " | undefined"
+	}
+	accessors := fmt.Sprintf("%sget %s(): %s {\n%sreturn this.#%s;\n%s}\n", t.indentation(1), property, fldType, t.indentation(2), property, t.indentation(1))
+	if !t.readonlyFields && !t.readonly {
+		accessors += fmt.Sprintf("\n%sset %s(value: %s) {\n%sthis.#%s = value;\n%s}\n", t.indentation(1), property, fldType, t.indentation(2), property, t.indentation(1))
+	} else {
+		t.getterOnly = append(t.getterOnly, property)
+	}
+	t.accessors = append(t.accessors, accessors)
 }
 
 func (t *typeScriptClassBuilder) addField(fld, fldType string) {
-	t.fields = append(t.fields, fmt.Sprint(t.indent, fld, ": ", fldType, ";"))
+	optional := strings.HasSuffix(fld, "?")
+	fld = t.propertyName(strings.TrimSuffix(fld, "?"))
+	if t.nullable {
+		fldType += " | null"
+		if optional && t.nullish {
+			fldType += " | undefined"
+		}
+	}
+	t.manifestFields = append(t.manifestFields, ManifestField{
+		Name:     fld,
+		TSType:   fldType,
+		Optional: optional,
+		Kind:     t.kind.String(),
+	})
+	private := t.isPrivate(fld)
+	if private {
+		t.addAccessors(fld, fldType, optional)
+		if optional { // Private fields can't be optional
+			if !strings.HasSuffix(fldType, " | undefined") {
+				fldType += " | undefined"
+			}
+			optional = false
+		}
+		fld = "#" + fld
+	} else if t.quoteNames {
+		fld = fmt.Sprintf("%q", fld)
+	} else {
+		fld = propertyKey(fld)
+	}
+	if optional {
+		fld += "?"
+	} else if t.definiteFields && t.defaultValue == "" {
+		fld += "!"
+	}
+	if t.tag != "" {
+		t.fields = append(t.fields, fmt.Sprint(t.indentation(1), "// tag: ", t.tag))
+	}
+	if t.doc != "" {
+		t.fields = append(t.fields, strings.TrimSuffix(jsDoc(t.doc, t.indentation(1)), "\n"))
+	}
+	if t.seeComments && t.see != "" {
+		t.fields = append(t.fields, fmt.Sprintf("%s/** @see %s */", t.indentation(1), t.see))
+	}
+	t.see = ""
+	for _, decorator := range t.decorators {
+		t.fields = append(t.fields, t.indentation(1)+decorator)
+	}
+	t.decorators = nil
+	if t.readonlyFields || t.readonly {
+		fld = "readonly " + fld
+	}
+	if t.defaultValue != "" {
+		fldType += " = " + t.defaultValue
+	}
+	t.fields = append(t.fields, fmt.Sprint(t.indentation(1), fld, ": ", fldType, ";"))
 }
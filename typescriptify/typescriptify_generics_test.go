@@ -0,0 +1,48 @@
+//go:build go1.18
+// +build go1.18
+
+package typescriptify
+
+import "testing"
+
+type User struct {
+	Name string `json:"name"`
+}
+
+type Response[T any] struct {
+	Data  T      `json:"data"`
+	Error string `json:"error"`
+}
+
+func TestGenericStruct(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Response[User]{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class User {
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+}
+export class ResponseUser {
+    data: User;
+    error: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.data = this.convertValues(source["data"], User);
+        this.error = source["error"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new ResponseUser({data: {name: "aaa"}}).data instanceof User`,
+	})
+}
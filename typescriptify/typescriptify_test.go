@@ -1,17 +1,26 @@
 package typescriptify
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tkrajina/typescriptify-golang-structs/example/models"
 )
 
 type Address struct {
@@ -27,6 +36,12 @@ type Dummy struct {
 	Something string `json:"something"`
 }
 
+type UserID string
+
+type Tags []string
+
+type DummiesByKey map[string]*Dummy
+
 type HasName struct {
 	Name string `json:"name"`
 }
@@ -61,7 +76,7 @@ export class Person {
         name: string;
         nicknames: string[];
 		addresses: Address[];
-		address?: Address;
+		address?: Address | null;
 		metadata: {[key:string]:string};
 		friends: Person[];
         a: Dummy;
@@ -93,7 +108,7 @@ export class Person {
         name: string;
         nicknames: string[];
 		addresses: Address[];
-		address?: Address;
+		address?: Address | null;
 		metadata: {[key:string]:string};
 		friends: Person[];
         a: Dummy;
@@ -123,7 +138,7 @@ class Person {
         name: string;
         nicknames: string[];
 		addresses: Address[];
-		address?: Address;
+		address?: Address | null;
 		metadata: {[key:string]:string};
 		friends: Person[];
         a: Dummy;
@@ -153,7 +168,7 @@ interface Person {
         name: string;
         nicknames: string[];
 		addresses: Address[];
-		address?: Address;
+		address?: Address | null;
 		metadata: {[key:string]:string};
 		friends: Person[];
         a: Dummy;
@@ -182,7 +197,7 @@ export class Person {
         name: string;
 		nicknames: string[];
 		addresses: Address[];
-		address?: Address;
+		address?: Address | null;
 		metadata: {[key:string]:string};
 		friends: Person[];
         a: Dummy;
@@ -233,7 +248,7 @@ class test_Person_test {
     name: string;
     nicknames: string[];
     addresses: test_Address_test[];
-    address?: test_Address_test;
+    address?: test_Address_test | null;
     metadata: {[key:string]:string};
     friends: test_Person_test[];
 	a: test_Dummy_test;
@@ -469,6 +484,90 @@ func TestRecursive(t *testing.T) {
 	testConverter(t, converter, true, desiredResult, nil)
 }
 
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Parent   *TreeNode   `json:"parent"`
+	Children []*TreeNode `json:"children"`
+}
+
+type Author struct {
+	Name  string  `json:"name"`
+	Books []*Book `json:"books"`
+}
+
+type Book struct {
+	Title  string  `json:"title"`
+	Author *Author `json:"author"`
+}
+
+func TestSelfReference(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(TreeNode{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class TreeNode {
+    name: string;
+    parent?: TreeNode | null;
+    children: TreeNode[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.parent = this.convertValues(source["parent"], TreeNode);
+        this.children = this.convertValues(source["children"], TreeNode);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new TreeNode({name: "b", parent: {name: "a"}}).parent instanceof TreeNode`,
+		`new TreeNode({name: "a", children: [{name: "b"}, null]}).children[0] instanceof TreeNode`,
+		`new TreeNode({name: "a", children: [{name: "b"}, null]}).children[1] === null`,
+	})
+}
+
+func TestMutuallyRecursive(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Author{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	// Every type is converted once, Book (only reachable through Author) before Author:
+	desiredResult := `export class Book {
+    title: string;
+    author?: Author | null;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.title = source["title"];
+        this.author = this.convertValues(source["author"], Author);
+    }
+
+	` + tsConvertValuesFunc + `
+}
+export class Author {
+    name: string;
+    books: Book[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.books = this.convertValues(source["books"], Book);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Author({books: [{title: "a", author: {name: "x"}}]}).books[0] instanceof Book`,
+		`new Author({books: [{title: "a", author: {name: "x"}}]}).books[0].author instanceof Author`,
+	})
+}
+
 func TestArrayOfArrays(t *testing.T) {
 	t.Parallel()
 	type Key struct {
@@ -735,6 +834,32 @@ export class Holliday {
 	}
 }
 
+func TestEnumNames(t *testing.T) {
+	t.Parallel()
+	for _, style := range []string{EnumStyleEnum, EnumStyleConstObject} {
+		converter := New().
+			AddEnum(allWeekdaysV1[:3]).
+			AddEnum(allGenders).
+			WithEnumStyle(style).
+			WithEnumNames(true).
+			WithBackupDir("")
+
+		result, err := converter.Convert(nil)
+		assert.Nil(t, err)
+		assert.Contains(t, result, `export const WeekdayNames: Readonly<Record<Weekday, string>> = {
+    0: "SUNDAY",
+    1: "MONDAY",
+    2: "TUESDAY",
+};`)
+		assert.NotContains(t, result, "GenderNames") // Not for string enums
+
+		testConverter(t, converter, true, result, []string{
+			`WeekdayNames[Weekday.MONDAY] === "MONDAY"`,
+			`WeekdayNames[2] === "TUESDAY"`,
+		})
+	}
+}
+
 type Gender string
 
 const (
@@ -767,6 +892,157 @@ export enum Gender {
 	testConverter(t, converter, true, desiredResult, nil)
 }
 
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusDeleted Status = "deleted"
+)
+
+func TestEnumMemberTransform(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		AddEnum([]struct {
+			Value  Status
+			TSName string
+		}{
+			{StatusActive, "StatusActive"},
+			{StatusDeleted, "StatusDeleted"},
+		}).
+		WithEnumMemberTransform(func(name string) string {
+			return strings.TrimPrefix(name, "Status")
+		}).
+		WithBackupDir("")
+
+	desiredResult := `
+export enum Status {
+	Active = "active",
+	Deleted = "deleted",
+}
+`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Status.Active === "active"`,
+	})
+}
+
+func TestMapKeyTypeTag(t *testing.T) {
+	t.Parallel()
+	type Inventory struct {
+		ByStatus map[string]Dummy  `json:"by_status" ts_key_type:"Status"`
+		Counts   map[string]int    `json:"counts" ts_key_type:"Status"`
+		Names    map[string]string `json:"names"`
+	}
+
+	converter := New().
+		AddEnum([]struct {
+			Value  Status
+			TSName string
+		}{
+			{StatusActive, "Active"},
+			{StatusDeleted, "Deleted"},
+		}).
+		Add(Inventory{}).
+		WithBackupDir("")
+
+	desiredResult := `export enum Status {
+	Active = "active",
+	Deleted = "deleted",
+}
+export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Inventory {
+    by_status: {[key in Status]?: Dummy};
+    counts: {[key in Status]?: number};
+    names: {[key: string]: string};
+
+    static createFrom(source: any = {}) {
+        return new Inventory(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.by_status = this.convertValues(source["by_status"], Dummy, true);
+        this.counts = source["counts"];
+        this.names = source["names"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Inventory.createFrom({"by_status": {"active": {"something": "x"}}}).by_status[Status.Active]?.something === "x"`,
+		`Inventory.createFrom({"counts": {"deleted": 2}}).counts[Status.Deleted] === 2`,
+	})
+}
+
+func TestEnumFromMap(t *testing.T) {
+	t.Parallel()
+	type Calendar struct {
+		Workdays []Weekday `json:"workdays"`
+		First    *Weekday  `json:"first"`
+	}
+
+	converter := New().
+		Add(Calendar{}).
+		AddEnum(map[string]Weekday{"SUNDAY": Sunday, "SATURDAY": Saturday, "MONDAY": Monday}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export enum Weekday {
+	SUNDAY = 0,
+	MONDAY = 1,
+	SATURDAY = 6,
+}
+export class Calendar {
+	workdays: Weekday[];
+	first?: Weekday;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestEnumStyles(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		AddEnum(allGenders).
+		WithEnumStyle(EnumStyleConstObject).
+		WithBackupDir("")
+
+	desiredResult := `
+export const Gender = {
+	MALE: "m",
+	FEMALE: "f",
+} as const;
+export type Gender = typeof Gender[keyof typeof Gender];
+`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Gender.MALE === "m"`,
+		`(Gender.FEMALE as Gender) === "f"`,
+	})
+
+	converter = New().
+		AddEnum(allGenders).
+		WithEnumStyle(EnumStyleConstEnum).
+		WithBackupDir("")
+
+	desiredResult = `
+export const enum Gender {
+	MALE = "m",
+	FEMALE = "f",
+}
+`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
 func TestConstructorWithReferences(t *testing.T) {
 	t.Parallel()
 	converter := New().
@@ -807,7 +1083,7 @@ export class Person {
     name: string;
     nicknames: string[];
     addresses: Address[];
-    address?: Address;
+    address?: Address | null;
     metadata: {[key:string]:string};
     friends: Person[];
     a: Dummy;
@@ -1008,3 +1284,6372 @@ func TestIgnoredPTR(t *testing.T) {
 `
 	testConverter(t, converter, true, desiredResult, nil)
 }
+
+func TestSpaceIndent(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		WithIndent("  ").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+  something: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "\n"+desiredResult, typeScriptCode)
+}
+
+func TestDefaultIndent(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "    ", New().Indent)
+}
+
+func TestCustomIndentDoesntCorruptOutput(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		WithIndent("ex").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "\nexport class Dummy {\nexsomething: string;\n}", typeScriptCode)
+}
+
+type Flag bool
+type Level int
+
+func TestNamedScalars(t *testing.T) {
+	t.Parallel()
+	type Settings struct {
+		Enabled Flag   `json:"enabled"`
+		Level   Level  `json:"level"`
+		Other   *Flag  `json:"other"`
+		Name    string `json:"name"`
+	}
+
+	converter := New().
+		Add(Settings{}).
+		WithNamedScalars(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export type Level = number;
+export type Flag = boolean;
+export class Settings {
+    enabled: Flag;
+    level: Level;
+    other?: Flag;
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.enabled = source["enabled"];
+        this.level = source["level"];
+        this.other = source["other"];
+        this.name = source["name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Settings({enabled: true}).enabled === true`,
+	})
+}
+
+func TestFreezeCreateFrom(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		WithCreateFromMethod(true).
+		WithFreezeCreateFrom(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}): Readonly<Dummy> {
+        return Object.freeze(new Dummy(source));
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Object.isFrozen(Dummy.createFrom({something: "aaa"}))`,
+		`Dummy.createFrom({something: "aaa"}).something === "aaa"`,
+	})
+}
+
+func TestStringEncodedFields(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		Active  bool    `json:"active,string"`
+		Balance float64 `json:"balance,string"`
+		Count   *int    `json:"count,string"`
+		Plain   bool    `json:"plain"`
+	}
+
+	converter := New().
+		Add(Account{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Account {
+    active: string;
+    balance: string;
+    count?: string;
+    plain: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.active = source["active"];
+        this.balance = source["balance"];
+        this.count = source["count"];
+        this.plain = source["plain"];
+    }
+}`
+	jsn := jsonizeOrPanic(Account{Active: true, Balance: 1.5})
+	assert.Equal(t, `{"active":"true","balance":"1.5","count":null,"plain":false}`, jsn)
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Account(` + jsn + `).active === "true"`,
+		`new Account(` + jsn + `).balance === "1.5"`,
+	})
+}
+
+func TestRootUnion(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Person{}).
+		Add(Dummy{}).
+		Add(Person{}).
+		WithRootUnion("AnyEntity").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+}
+export class Address {
+    duration: number;
+    text?: string;
+}
+export class Person {
+    name: string;
+    nicknames: string[];
+    addresses: Address[];
+    address?: Address | null;
+    metadata: {[key:string]:string};
+    friends: Person[];
+    a: Dummy;
+}
+export type AnyEntity = Person | Dummy;`
+	testConverter(t, converter, false, desiredResult, nil)
+}
+
+func TestPointersInMapAndSliceElements(t *testing.T) {
+	t.Parallel()
+	type WithWrappedElements struct {
+		MapOfSlicePtrs map[string]*[]Address `json:"mapOfSlicePtrs"`
+		SliceOfMapPtrs []*map[string]int     `json:"sliceOfMapPtrs"`
+		SliceOfPtrs    [][]*Address          `json:"sliceOfPtrs"`
+	}
+
+	converter := New().
+		Add(WithWrappedElements{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+}
+export class WithWrappedElements {
+    mapOfSlicePtrs: {[key: string]: Address[]};
+    sliceOfMapPtrs: {[key: string]: number}[];
+    sliceOfPtrs: Address[][];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.mapOfSlicePtrs = this.convertValues(source["mapOfSlicePtrs"], Address, true);
+        this.sliceOfMapPtrs = source["sliceOfMapPtrs"];
+        this.sliceOfPtrs = this.convertValues(source["sliceOfPtrs"], Address);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn := jsonizeOrPanic(WithWrappedElements{
+		MapOfSlicePtrs: map[string]*[]Address{"aaa": {{Text1: "txt1"}}},
+		SliceOfMapPtrs: []*map[string]int{{"bbb": 7}},
+		SliceOfPtrs:    [][]*Address{{{Text1: "txt2"}}},
+	})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new WithWrappedElements(` + jsn + `).mapOfSlicePtrs.aaa[0] instanceof Address`,
+		`new WithWrappedElements(` + jsn + `).mapOfSlicePtrs.aaa[0].text === "txt1"`,
+		`new WithWrappedElements(` + jsn + `).sliceOfMapPtrs[0].bbb === 7`,
+		`new WithWrappedElements(` + jsn + `).sliceOfPtrs[0][0] instanceof Address`,
+	})
+}
+
+func TestSkippedFieldsComment(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		Name         string `json:"name"`
+		Password     string `json:"-"`
+		internalFlag bool
+		Untagged     string
+	}
+
+	converter := New().
+		Add(Account{}).
+		WithSkippedFieldsComment(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Account {
+    name: string;
+
+    static createFrom(source: any = {}) {
+        return new Account(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+
+    // skipped: Password, internalFlag, Untagged
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	typeScript, err := New().
+		Add(Account{}).
+		WithSkippedFieldsComment(true).
+		WithInterface(true).
+		WithBackupDir("").
+		Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `export interface Account {
+    name: string;
+    // skipped: Password, internalFlag, Untagged
+}`, strings.TrimSpace(typeScript))
+}
+
+func TestQuoteAllPropertyNames(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Address{}).
+		WithQuoteAllPropertyNames(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    "duration": number;
+    "text"?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Address({duration: 1}).duration === 1`,
+	})
+}
+
+func TestJSONTagWithSpaces(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Name  string `json:" name "`
+		Count int    `json:"\tcount ,omitempty"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := "export interface Person {\n    name: string;\n    count?: number;\n}"
+	testConverter(t, converter, true, desiredResult, nil)
+
+	// The names are trimmed independently of the indentation:
+	type Range struct {
+		From int `json:" from "`
+		To   int `json:"to\t,omitempty"`
+	}
+	converter = New().
+		Add(Range{}).
+		WithIndent("\t").
+		WithInterface(true).
+		WithBackupDir("")
+	desiredResult = "export interface Range {\n\tfrom: number;\n\tto?: number;\n}"
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestNonIdentifierPropertyNames(t *testing.T) {
+	t.Parallel()
+	type Headers struct {
+		ContentType string `json:"content-type"`
+		TwoFactor   bool   `json:"2fa"`
+		Name        string `json:"name"`
+	}
+
+	converter := New().
+		Add(Headers{}).
+		WithToJSON(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Headers {
+    "content-type": string;
+    "2fa": boolean;
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this["content-type"] = source["content-type"];
+        this["2fa"] = source["2fa"];
+        this.name = source["name"];
+    }
+
+    toJSON(): any {
+        return {
+            "content-type": this["content-type"],
+            "2fa": this["2fa"],
+            "name": this.name,
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Headers({"content-type": "text/html"})["content-type"] === "text/html"`,
+		`new Headers({"2fa": true})["2fa"] === true`,
+	})
+}
+
+func TestLogSkippedFields(t *testing.T) {
+	t.Parallel()
+	type Skipped struct {
+		Name     string `json:"name"`
+		Untagged string
+		Ignored  string       `json:"-"`
+		Callback func() error `json:"callback"`
+	}
+
+	var logged []string
+	converter := New().
+		Add(Skipped{}).
+		WithLogf(func(format string, args ...interface{}) {
+			logged = append(logged, fmt.Sprintf(format, args...))
+		}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	_, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"skipped field Skipped.Untagged: no JSON name (untagged or empty name in the json tag)",
+		`skipped field Skipped.Ignored: ignored with json:"-"`,
+		"skipped field Skipped.Callback: func field without ts_type",
+	}, logged)
+}
+
+func TestStrictUnsupportedKinds(t *testing.T) {
+	t.Parallel()
+	type Worker struct {
+		Name   string       `json:"name"`
+		Jobs   chan int     `json:"jobs"`
+		Queues []chan int   `json:"queues"`
+		Signal complex128   `json:"signal"`
+		Custom chan float64 `json:"custom" ts_type:"number[]"`
+	}
+
+	var logged []string
+	converter := New().
+		Add(Worker{}).
+		WithLogf(func(format string, args ...interface{}) {
+			logged = append(logged, fmt.Sprintf(format, args...))
+		}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Worker {
+    name: string;
+    custom: number[];
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+	assert.Equal(t, []string{
+		"skipped field Worker.Jobs: chan field without ts_type",
+		"skipped field Worker.Queues: chan field without ts_type",
+		"skipped field Worker.Signal: complex128 field without ts_type",
+	}, logged[:3])
+
+	_, err := New().Add(Worker{}).WithStrict(true).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "cannot convert Worker.Jobs: chan fields can't be represented in TypeScript (without a ts_type)", err.Error())
+}
+
+func TestOptionalTag(t *testing.T) {
+	t.Parallel()
+	type Options struct {
+		Required    string  `json:"required"`
+		Forced      string  `json:"forced" ts_optional:"true"`
+		OmitEmpty   string  `json:"omit_empty,omitempty" ts_optional:"false"`
+		Pointer     *string `json:"pointer" ts_optional:"false"`
+		NotOverride *string `json:"not_override"`
+	}
+
+	converter := New().
+		Add(Options{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Options {
+    required: string;
+    forced?: string;
+    omit_empty: string;
+    pointer: string;
+    not_override?: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestSetCustomCode(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		SetCustomCode("Dummy", "    getSomething() {\n        return this.something;\n    }").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+    //[Dummy:]
+    getSomething() {
+        return this.something;
+    }
+
+    //[end]
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Dummy({something: "aaa"}).getSomething() === "aaa"`,
+	})
+
+	// Custom code set with the API overrides the code loaded from the file:
+	typeScriptCode, err := converter.Convert(map[string]string{"Dummy": "    // from file"})
+	assert.Nil(t, err)
+	assert.Contains(t, typeScriptCode, "getSomething()")
+	assert.NotContains(t, typeScriptCode, "// from file")
+}
+
+func TestEmptyCustomCodeBlocks(t *testing.T) {
+	t.Parallel()
+	for _, omit := range []bool{false, true} {
+		converter := New().
+			Add(Dummy{}).
+			SetCustomCode("Dummy", "  \n\t\n").
+			WithOmitEmptyCustomBlocks(omit).
+			WithBackupDir("")
+
+		typeScriptCode, err := converter.Convert(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, !omit, strings.Contains(typeScriptCode, CustomCodeStartPrefix+"Dummy:]"), "omit=%v", omit)
+
+		// Empty blocks in the file are parsed as no code, and never kept:
+		customCode, err := ParseCustomCode(strings.NewReader("class Dummy {\n    //[Dummy:]\n\n    //[end]\n}\n"))
+		assert.Nil(t, err)
+		typeScriptCode, err = New().Add(Dummy{}).WithOmitEmptyCustomBlocks(omit).WithBackupDir("").Convert(customCode)
+		assert.Nil(t, err)
+		assert.NotContains(t, typeScriptCode, CustomCodeStartPrefix)
+		assert.NotContains(t, typeScriptCode, CustomCodeEnd)
+	}
+}
+
+func TestRootUnionDispatcher(t *testing.T) {
+	t.Parallel()
+	type Circle struct {
+		Kind   string  `json:"kind"`
+		Radius float64 `json:"radius"`
+	}
+	type Square struct {
+		Kind string  `json:"kind"`
+		Side float64 `json:"side"`
+	}
+
+	converter := New().
+		Add(Circle{}).
+		Add(NewStruct(Square{}).WithDiscriminatorValue("square")).
+		WithRootUnion("Shape").
+		WithRootUnionDiscriminator("kind").
+		WithConstructor(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Circle {
+    kind: string;
+    radius: number;
+
+    static createFrom(source: any = {}) {
+        return new Circle(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.kind = source["kind"];
+        this.radius = source["radius"];
+    }
+}
+export class Square {
+    kind: string;
+    side: number;
+
+    static createFrom(source: any = {}) {
+        return new Square(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.kind = source["kind"];
+        this.side = source["side"];
+    }
+}
+export type Shape = Circle | Square;
+export function createFromAny(source: any = {}): Shape {
+    if ('string' === typeof source) source = JSON.parse(source);
+    switch (source["kind"]) {
+        case "Circle":
+            return Circle.createFrom(source);
+        case "square":
+            return Square.createFrom(source);
+    }
+    throw new Error("unknown kind: " + source["kind"]);
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`createFromAny({kind: "Circle", radius: 1}) instanceof Circle`,
+		`createFromAny({kind: "square", side: 2}) instanceof Square`,
+		`(createFromAny({kind: "square", side: 2}) as Square).side === 2`,
+	})
+}
+
+func TestOmitemptyPtrToSlice(t *testing.T) {
+	t.Parallel()
+	type WithPtrSlice struct {
+		Addresses *[]Address `json:"addresses,omitempty"`
+	}
+
+	converter := New().
+		Add(WithPtrSlice{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+}
+export class WithPtrSlice {
+    addresses?: Address[] | null;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.addresses = this.convertValues(source["addresses"], Address);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	var nilSlice []Address
+	testConverter(t, converter, true, desiredResult, []string{
+		`new WithPtrSlice({}).addresses === undefined`,
+		`new WithPtrSlice(` + jsonizeOrPanic(WithPtrSlice{Addresses: &nilSlice}) + `).addresses === null`,
+		`new WithPtrSlice(` + jsonizeOrPanic(WithPtrSlice{Addresses: &[]Address{{Text1: "txt"}}}) + `).addresses?.[0] instanceof Address`,
+	})
+}
+
+func TestExplicitUndefined(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Address{}).
+		WithExplicitUndefined(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"] !== undefined ? source["text"] : undefined;
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Address({}).text === undefined`,
+		`new Address({text: "aaa"}).text === "aaa"`,
+	})
+}
+
+func TestTrailingCommas(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(WithMap{}).
+		WithTrailingCommas(true).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+}
+export class WithMap {
+    simpleMap: {[key: string]: number,};
+    mapObjects: {[key: string]: Address,};
+    ptrMapObjects?: {[key: string]: Address,};
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestComputedField(t *testing.T) {
+	t.Parallel()
+	type Author struct {
+		Name    string `json:"name"`
+		Surname string `json:"surname"`
+	}
+
+	converter := New().
+		Add(Author{}).
+		AddComputedField("Author", "fullName: string", `source["name"] + " " + source["surname"]`).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Author {
+    name: string;
+    surname: string;
+    fullName: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.surname = source["surname"];
+        this.fullName = source["name"] + " " + source["surname"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Author({name: "Jane", surname: "Doe"}).fullName === "Jane Doe"`,
+	})
+}
+
+type EmbeddedA struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Code  string `json:"Code"`
+}
+
+type EmbeddedB struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Code  string
+}
+
+type WithConflictingEmbeds struct {
+	*EmbeddedA
+	*EmbeddedB
+	Title string `json:"title"`
+}
+
+func TestConflictingEmbeddedFields(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(WithConflictingEmbeds{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	// "id" is ambiguous, "title" is defined on the shallowest level, and "Code" is tagged only in EmbeddedA:
+	desiredResult := `export class WithConflictingEmbeds {
+    Code: string;
+    title: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	byts, err := json.Marshal(WithConflictingEmbeds{EmbeddedA: &EmbeddedA{ID: "a", Code: "c"}, EmbeddedB: &EmbeddedB{ID: "b"}, Title: "t"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Code":"c","title":"t"}`, string(byts))
+}
+
+func TestReadonlyTypeAlias(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Person{}).
+		WithReadonlyTypeAlias(true).
+		WithBackupDir("")
+
+	desiredResult := `export type Dummy = Readonly<{
+    something: string;
+}>;
+export type Address = Readonly<{
+    duration: number;
+    text?: string;
+}>;
+export type Person = Readonly<{
+    name: string;
+    nicknames: string[];
+    addresses: Address[];
+    address?: Address | null;
+    metadata: {[key:string]:string};
+    friends: Person[];
+    a: Dummy;
+}>;`
+	testConverter(t, converter, true, desiredResult, []string{
+		`({something: "aaa"} as Dummy).something === "aaa"`,
+	})
+}
+
+func TestTransformWithSource(t *testing.T) {
+	t.Parallel()
+	type Range struct {
+		From int `json:"from"`
+		To   int `json:"to" ts_transform:"Math.max(__VALUE__, __SOURCE__[\"from\"])"`
+	}
+
+	converter := New().
+		Add(Range{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Range {
+    from: number;
+    to: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.from = source["from"];
+        this.to = Math.max(source["to"], source["from"]);
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Range({from: 5, to: 3}).to === 5`,
+		`new Range({from: 5, to: 7}).to === 7`,
+	})
+}
+
+func TestDeclarationOnly(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Holliday{}).
+		Add(WithMap{}).
+		AddEnum(allWeekdaysV1).
+		WithDeclarationOnly(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	desiredResult := `export declare enum Weekday {
+	SUNDAY = 0,
+	MONDAY = 1,
+	TUESDAY = 2,
+	WEDNESDAY = 3,
+	THURSDAY = 4,
+	FRIDAY = 5,
+	SATURDAY = 6,
+}
+export declare class Holliday {
+    name: string;
+    weekday: Weekday;
+
+    static createFrom(source?: any): Holliday;
+
+    constructor(source?: any);
+}
+export declare class Address {
+    duration: number;
+    text?: string;
+
+    static createFrom(source?: any): Address;
+
+    constructor(source?: any);
+}
+export declare class WithMap {
+    simpleMap: {[key: string]: number};
+    mapObjects: {[key: string]: Address};
+    ptrMapObjects?: {[key: string]: Address};
+
+    static createFrom(source?: any): WithMap;
+
+    constructor(source?: any);
+
+    convertValues(a: any, classs: any, asMap?: boolean): any;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestInstantiateMissingStructs(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy    Dummy  `json:"dummy"`
+		DummyPtr *Dummy `json:"dummyPtr"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithInstantiateMissingStructs(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    dummy: Dummy;
+    dummyPtr?: Dummy | null;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.dummy = this.convertValues(source["dummy"] || {}, Dummy);
+        this.dummyPtr = this.convertValues(source["dummyPtr"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Parent({}).dummy instanceof Dummy`,
+		`new Parent({}).dummyPtr === undefined`,
+	})
+}
+
+func TestPrefixedRootReferences(t *testing.T) {
+	t.Parallel()
+	type Foo struct {
+		Name string `json:"name"`
+	}
+	type Bar struct {
+		Foo    Foo            `json:"foo"`
+		Foos   []Foo          `json:"foos"`
+		FooMap map[string]Foo `json:"fooMap"`
+	}
+
+	converter := New().
+		Add(Foo{}).
+		Add(Bar{}).
+		WithPrefix("I").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class IFoo {
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+}
+export class IBar {
+    foo: IFoo;
+    foos: IFoo[];
+    fooMap: {[key: string]: IFoo};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.foo = this.convertValues(source["foo"], IFoo);
+        this.foos = this.convertValues(source["foos"], IFoo);
+        this.fooMap = this.convertValues(source["fooMap"], IFoo, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new IBar({foo: {name: "aaa"}}).foo instanceof IFoo`,
+		`new IBar({fooMap: {x: {name: "aaa"}}}).fooMap.x instanceof IFoo`,
+	})
+}
+
+type CircleShape struct {
+	Radius float64 `json:"radius"`
+}
+
+type SquareShape struct {
+	Side float64 `json:"side"`
+}
+
+type TriangleShape struct {
+	Type string    `json:"type"`
+	Base []float64 `json:"base"`
+}
+
+func TestSumType(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		AddSumTypeWithDiscriminator("Shape", "type", []interface{}{CircleShape{}, SquareShape{}, TriangleShape{}}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class CircleShape {
+    radius: number;
+    type: "CircleShape" = "CircleShape";
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.radius = source["radius"];
+    }
+}
+export class SquareShape {
+    side: number;
+    type: "SquareShape" = "SquareShape";
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.side = source["side"];
+    }
+}
+export class TriangleShape {
+    type: string;
+    base: number[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.type = source["type"];
+        this.base = source["base"];
+    }
+}
+export type Shape = CircleShape | SquareShape | TriangleShape;`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new CircleShape({radius: 1}).type === "CircleShape"`,
+	})
+
+	converter = New().
+		AddSumType("Shape", []interface{}{CircleShape{}, SquareShape{}}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult = `export interface CircleShape {
+    radius: number;
+}
+export interface SquareShape {
+    side: number;
+}
+export type Shape = CircleShape | SquareShape;`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestInterfaceUnion(t *testing.T) {
+	t.Parallel()
+	type Figure interface{}
+	type Message struct {
+		Payload  interface{}   `json:"payload"`
+		Payloads []interface{} `json:"payloads"`
+		Figure   Figure        `json:"figure"`
+	}
+
+	converter := New().
+		Add(Message{}).
+		AddUnion(reflect.TypeOf((*interface{})(nil)).Elem(), []interface{}{CircleShape{}, SquareShape{}}).
+		AddUnion(reflect.TypeOf((*Figure)(nil)).Elem(), []interface{}{CircleShape{}, SquareShape{}}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Message {
+    payload: CircleShape | SquareShape;
+    payloads: (CircleShape | SquareShape)[];
+    figure: Figure;
+}
+export interface CircleShape {
+    radius: number;
+}
+export interface SquareShape {
+    side: number;
+}
+export type Figure = CircleShape | SquareShape;`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestNullablePointerStructs(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Value   Dummy  `json:"value"`
+		Pointer *Dummy `json:"pointer"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Dummy {
+    something: string;
+}
+export interface Parent {
+    value: Dummy;
+    pointer?: Dummy | null;
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(` + jsonizeOrPanic(Parent{}) + ` as Parent).pointer === null`,
+	})
+}
+
+func TestValueAndPointerToSameStruct(t *testing.T) {
+	t.Parallel()
+	// The pointer is converted first:
+	type Parent struct {
+		Pointer *Dummy `json:"pointer"`
+		Value   Dummy  `json:"value"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    pointer?: Dummy | null;
+    value: Dummy;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.pointer = this.convertValues(source["pointer"], Dummy);
+        this.value = this.convertValues(source["value"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Parent({value: {something: "a"}, pointer: {something: "b"}}).value instanceof Dummy`,
+		`new Parent({value: {something: "a"}, pointer: {something: "b"}}).pointer instanceof Dummy`,
+		`new Parent({value: {something: "a"}, pointer: null}).pointer === null`,
+	})
+}
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Name    string         `json:"name"`
+		Address *Address       `json:"address"`
+		Tags    map[string]int `json:"tags"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithBackupDir("")
+
+	byts, err := converter.ConvertToManifest()
+	assert.Nil(t, err)
+
+	var manifest Manifest
+	assert.Nil(t, json.Unmarshal(byts, &manifest))
+	assert.Equal(t, Manifest{Types: []ManifestType{
+		{
+			Name:   "Address",
+			GoType: "typescriptify.Address",
+			Fields: []ManifestField{
+				{Name: "duration", TSType: "number", Kind: "float64"},
+				{Name: "text", TSType: "string", Optional: true, Kind: "string"},
+			},
+		},
+		{
+			Name:   "Parent",
+			GoType: "typescriptify.Parent",
+			Fields: []ManifestField{
+				{Name: "name", TSType: "string", Kind: "string"},
+				{Name: "address", TSType: "Address | null", Optional: true, Kind: "struct"},
+				{Name: "tags", TSType: "{[key: string]: number}", Kind: "map"},
+			},
+		},
+	}}, manifest)
+	assert.Contains(t, string(byts), `"tsType": "Address | null"`)
+}
+
+func TestConvertToGraphQL(t *testing.T) {
+	t.Parallel()
+	type Profile struct {
+		Name      string            `json:"name"`
+		Count     int               `json:"count"`
+		Rank      int32             `json:"rank"`
+		Score     float64           `json:"score,omitempty"`
+		Active    bool              `json:"active"`
+		Nickname  *string           `json:"nickname"`
+		Tags      []string          `json:"tags"`
+		Addresses []Address         `json:"addresses"`
+		Previous  []*Address        `json:"previous"`
+		Home      *Address          `json:"home"`
+		Extra     map[string]string `json:"extra"`
+		Created   time.Time         `json:"created"`
+	}
+
+	converter := New().
+		Add(Profile{}).
+		WithBackupDir("")
+
+	sdl, err := converter.ConvertToGraphQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `scalar Int64
+scalar JSON
+
+type Address {
+    duration: Float!
+    text: String
+}
+
+type Profile {
+    name: String!
+    count: Int64!
+    rank: Int!
+    score: Float
+    active: Boolean!
+    nickname: String
+    tags: [String!]!
+    addresses: [Address!]!
+    previous: [Address]!
+    home: Address
+    extra: JSON!
+    created: String!
+}
+`, sdl)
+}
+
+func TestConvertToGraphQLErrors(t *testing.T) {
+	t.Parallel()
+	type Headers struct {
+		ContentType string `json:"content-type"`
+	}
+	type Empty struct {
+		Hidden string `json:"-"`
+	}
+
+	_, err := New().Add(Headers{}).WithBackupDir("").ConvertToGraphQL()
+	assert.NotNil(t, err)
+	assert.Equal(t, "cannot convert typescriptify.Headers.content-type: it isn't a valid GraphQL name", err.Error())
+
+	_, err = New().Add(Empty{}).WithBackupDir("").ConvertToGraphQL()
+	assert.NotNil(t, err)
+	assert.Equal(t, "cannot convert typescriptify.Empty: GraphQL types must have at least one field", err.Error())
+}
+
+func TestConvertToJSONSchema(t *testing.T) {
+	t.Parallel()
+	type Profile struct {
+		Name     string          `json:"name"`
+		Count    int             `json:"count"`
+		Score    float64         `json:"score,omitempty"`
+		Active   bool            `json:"active"`
+		Tags     []string        `json:"tags"`
+		Previous []*Address      `json:"previous"`
+		Home     *Address        `json:"home"`
+		Extra    map[string]int  `json:"extra"`
+		Point    [2]float64      `json:"point"`
+		Avatar   []byte          `json:"avatar,omitempty"`
+		Payload  json.RawMessage `json:"payload"`
+		Created  time.Time       `json:"created"`
+	}
+
+	converter := New().
+		Add(Profile{}).
+		WithIndent("  ").
+		WithBackupDir("")
+
+	schema, err := converter.ConvertToJSONSchema()
+	assert.Nil(t, err)
+	assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "Address": {
+      "type": "object",
+      "properties": {
+        "duration": {
+          "type": "number"
+        },
+        "text": {
+          "type": "string"
+        }
+      },
+      "required": [
+        "duration"
+      ]
+    },
+    "Profile": {
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "count": {
+          "type": "integer"
+        },
+        "score": {
+          "type": "number"
+        },
+        "active": {
+          "type": "boolean"
+        },
+        "tags": {
+          "anyOf": [
+            {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            {
+              "type": "null"
+            }
+          ]
+        },
+        "previous": {
+          "anyOf": [
+            {
+              "type": "array",
+              "items": {
+                "anyOf": [
+                  {
+                    "$ref": "#/$defs/Address"
+                  },
+                  {
+                    "type": "null"
+                  }
+                ]
+              }
+            },
+            {
+              "type": "null"
+            }
+          ]
+        },
+        "home": {
+          "anyOf": [
+            {
+              "$ref": "#/$defs/Address"
+            },
+            {
+              "type": "null"
+            }
+          ]
+        },
+        "extra": {
+          "anyOf": [
+            {
+              "type": "object",
+              "additionalProperties": {
+                "type": "integer"
+              }
+            },
+            {
+              "type": "null"
+            }
+          ]
+        },
+        "point": {
+          "type": "array",
+          "items": {
+            "type": "number"
+          },
+          "minItems": 2,
+          "maxItems": 2
+        },
+        "avatar": {
+          "anyOf": [
+            {
+              "type": "string",
+              "contentEncoding": "base64"
+            },
+            {
+              "type": "null"
+            }
+          ]
+        },
+        "payload": {},
+        "created": {
+          "type": "string",
+          "format": "date-time"
+        }
+      },
+      "required": [
+        "name",
+        "count",
+        "active",
+        "tags",
+        "previous",
+        "extra",
+        "point",
+        "payload",
+        "created"
+      ]
+    }
+  }
+}
+`, schema)
+
+	var document map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(schema), &document))
+
+	defs := document["$defs"].(map[string]interface{})
+	for _, profile := range []Profile{
+		{},
+		{
+			Name:     "Jane",
+			Tags:     []string{"a"},
+			Previous: []*Address{nil, {Duration: 1}},
+			Home:     &Address{Duration: 2, Text1: "home"},
+			Extra:    map[string]int{"a": 1},
+			Avatar:   []byte("x"),
+			Payload:  json.RawMessage(`{"a":[1]}`),
+		},
+	} {
+		byts, err := json.Marshal(profile)
+		assert.Nil(t, err)
+		var value interface{}
+		assert.Nil(t, json.Unmarshal(byts, &value))
+		assert.Nil(t, validateJSONSchema(defs, defs["Profile"].(map[string]interface{}), value), string(byts))
+	}
+}
+
+// validateJSONSchema validates the value against the (subset of) JSON Schema generated by ConvertToJSONSchema.
+func validateJSONSchema(defs map[string]interface{}, schema map[string]interface{}, value interface{}) error {
+	if ref, is := schema["$ref"].(string); is {
+		return validateJSONSchema(defs, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{}), value)
+	}
+	if anyOf, is := schema["anyOf"].([]interface{}); is {
+		for _, option := range anyOf {
+			if validateJSONSchema(defs, option.(map[string]interface{}), value) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%#v doesn't match any of %#v", value, anyOf)
+	}
+	switch schema["type"] {
+	case nil:
+		return nil
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%#v is not null", value)
+		}
+	case "string":
+		if _, is := value.(string); !is {
+			return fmt.Errorf("%#v is not a string", value)
+		}
+	case "boolean":
+		if _, is := value.(bool); !is {
+			return fmt.Errorf("%#v is not a boolean", value)
+		}
+	case "number", "integer":
+		number, is := value.(float64)
+		if !is || (schema["type"] == "integer" && number != float64(int64(number))) {
+			return fmt.Errorf("%#v is not a %s", value, schema["type"])
+		}
+	case "array":
+		items, is := value.([]interface{})
+		if !is {
+			return fmt.Errorf("%#v is not an array", value)
+		}
+		if min, has := schema["minItems"].(float64); has && float64(len(items)) < min {
+			return fmt.Errorf("%#v has less than %v items", value, min)
+		}
+		if max, has := schema["maxItems"].(float64); has && float64(len(items)) > max {
+			return fmt.Errorf("%#v has more than %v items", value, max)
+		}
+		for _, item := range items {
+			if err := validateJSONSchema(defs, schema["items"].(map[string]interface{}), item); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, is := value.(map[string]interface{})
+		if !is {
+			return fmt.Errorf("%#v is not an object", value)
+		}
+		if required, has := schema["required"].([]interface{}); has {
+			for _, name := range required {
+				if _, found := object[name.(string)]; !found {
+					return fmt.Errorf("%#v has no %s", value, name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, fieldValue := range object {
+			fieldSchema, found := properties[name].(map[string]interface{})
+			if !found {
+				fieldSchema, found = schema["additionalProperties"].(map[string]interface{})
+			}
+			if !found {
+				continue
+			}
+			if err := validateJSONSchema(defs, fieldSchema, fieldValue); err != nil {
+				return fmt.Errorf("%s: %s", name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func TestConvertToPythonDataclasses(t *testing.T) {
+	t.Parallel()
+	type Profile struct {
+		Name      string         `json:"name"`
+		Count     int            `json:"count"`
+		Score     float64        `json:"score,omitempty"`
+		Active    bool           `json:"active"`
+		Tags      []string       `json:"tags"`
+		Addresses []Address      `json:"addresses"`
+		Home      *Address       `json:"home"`
+		Extra     map[string]int `json:"extra"`
+		Created   time.Time      `json:"created"`
+	}
+
+	converter := New().
+		Add(Profile{}).
+		WithBackupDir("")
+
+	python, err := converter.ConvertToPythonDataclasses()
+	assert.Nil(t, err)
+	assert.Equal(t, `from __future__ import annotations
+
+from dataclasses import dataclass
+from datetime import datetime
+from typing import Dict, List, Optional
+
+
+@dataclass
+class Address:
+    duration: float
+    text: Optional[str]
+
+
+@dataclass
+class Profile:
+    name: str
+    count: int
+    score: Optional[float]
+    active: bool
+    tags: List[str]
+    addresses: List[Address]
+    home: Optional[Address]
+    extra: Dict[str, int]
+    created: datetime
+`, python)
+}
+
+func TestConvertToPythonDataclassesAttributeNames(t *testing.T) {
+	t.Parallel()
+	type Style struct {
+		Class      string `json:"class"`
+		FontSize   int    `json:"font-size"`
+		Columns    int    `json:"2columns"`
+		Background string `json:"background"`
+	}
+
+	converter := New().
+		Add(Style{}).
+		WithIndent("\t").
+		WithBackupDir("")
+
+	python, err := converter.ConvertToPythonDataclasses()
+	assert.Nil(t, err)
+	assert.Equal(t, `from __future__ import annotations
+
+from dataclasses import dataclass, field
+
+
+@dataclass
+class Style:
+	class_: str = field(metadata={"json": "class"})
+	font_size: int = field(metadata={"json": "font-size"})
+	_2columns: int = field(metadata={"json": "2columns"})
+	background: str
+`, python)
+
+	type Colliding struct {
+		FontSize  int `json:"font-size"`
+		FontSize2 int `json:"font_size"`
+	}
+	_, err = New().Add(Colliding{}).WithBackupDir("").ConvertToPythonDataclasses()
+	assert.NotNil(t, err)
+	assert.Equal(t, "cannot convert typescriptify.Colliding.font_size: font-size and font_size are both converted to the attribute font_size", err.Error())
+}
+
+func TestFormattersWithStringEncodedAndTSTypeFields(t *testing.T) {
+	t.Parallel()
+	type Payment struct {
+		ID     int64      `json:"id,string"`
+		Amount *float64   `json:"amount,string"`
+		Price  Cents      `json:"price"`
+		Paid   NumberTime `json:"paid" ts_type:"number"`
+		Meta   string     `json:"meta" ts_type:"Record<string, number>"`
+	}
+
+	converter := New().
+		Add(Payment{}).
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, typeScriptCode, "    id: string;\n    amount?: string;\n    price: bigint;\n    paid: number;\n    meta: Record<string, number>;\n")
+
+	schema, err := converter.ConvertToJSONSchema()
+	assert.Nil(t, err)
+	assert.Contains(t, schema, `"properties": {
+                "id": {
+                    "type": "string"
+                },
+                "amount": {
+                    "anyOf": [
+                        {
+                            "type": "string"
+                        },
+                        {
+                            "type": "null"
+                        }
+                    ]
+                },
+                "price": {},
+                "paid": {
+                    "type": "number"
+                },
+                "meta": {}
+            },`)
+	var document map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(schema), &document))
+	defs := document["$defs"].(map[string]interface{})
+	amount := 1.5
+	byts, err := json.Marshal(Payment{ID: 1, Amount: &amount, Price: 250, Paid: NumberTime(time.Unix(10, 0))})
+	assert.Nil(t, err)
+	var value interface{}
+	assert.Nil(t, json.Unmarshal(byts, &value))
+	assert.Nil(t, validateJSONSchema(defs, defs["Payment"].(map[string]interface{}), value), string(byts))
+
+	sdl, err := converter.ConvertToGraphQL()
+	assert.Nil(t, err)
+	assert.Equal(t, "scalar JSON\n\ntype Payment {\n    id: String!\n    amount: String\n    price: JSON!\n    paid: Float!\n    meta: JSON!\n}\n", sdl)
+
+	python, err := converter.ConvertToPythonDataclasses()
+	assert.Nil(t, err)
+	assert.Contains(t, python, "class Payment:\n    id: str\n    amount: Optional[str]\n    price: Any\n    paid: float\n    meta: Any\n")
+}
+
+func TestFormattersWithUnsupportedFields(t *testing.T) {
+	t.Parallel()
+	type Job struct {
+		Name     string         `json:"name"`
+		Updates  chan int       `json:"updates"`
+		Ratio    complex128     `json:"ratio"`
+		Pointer  unsafe.Pointer `json:"pointer"`
+		Callback func()         `json:"callback"`
+	}
+
+	// Skipped, like in the TypeScript code:
+	converter := New().
+		Add(Job{}).
+		WithBackupDir("")
+	sdl, err := converter.ConvertToGraphQL()
+	assert.Nil(t, err)
+	assert.Equal(t, "type Job {\n    name: String!\n}\n", sdl)
+	schema, err := converter.ConvertToJSONSchema()
+	assert.Nil(t, err)
+	assert.Contains(t, schema, `"properties": {
+                "name": {
+                    "type": "string"
+                }
+            },`)
+	python, err := converter.ConvertToPythonDataclasses()
+	assert.Nil(t, err)
+	assert.Contains(t, python, "class Job:\n    name: str\n")
+
+	converter.WithStrict(true)
+	for _, convert := range []func() (string, error){converter.ConvertToGraphQL, converter.ConvertToJSONSchema, converter.ConvertToPythonDataclasses} {
+		_, err = convert()
+		assert.NotNil(t, err)
+		assert.Equal(t, "cannot convert Job.Updates: chan fields can't be represented in TypeScript (without a ts_type)", err.Error())
+	}
+}
+func TestEmbeddedInterface(t *testing.T) {
+	t.Parallel()
+	type WithStringer struct {
+		fmt.Stringer
+		Name string `json:"name"`
+	}
+
+	var logged []string
+	converter := New().
+		Add(WithStringer{}).
+		WithWarnEmbeddedInterfaces(true).
+		WithLogf(func(format string, args ...interface{}) {
+			logged = append(logged, fmt.Sprintf(format, args...))
+		}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface WithStringer {
+    name: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+	assert.Equal(t, 1, len(embeddedInterfaces(reflect.TypeOf(WithStringer{}))))
+	assert.Contains(t, logged, "embedded interface fmt.Stringer in typescriptify.WithStringer is ignored")
+}
+
+func TestCreateFromMethodName(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Name string  `json:"name"`
+		Kids []Dummy `json:"kids"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithCreateFromMethodName("fromJSON").
+		WithLiteralCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithConstructor(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static fromJSON(source: any = {}): Dummy {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            something: source["something"],
+        } as Dummy;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    name: string;
+    kids: Dummy[];
+
+    static fromJSON(source: any = {}): Parent {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            name: source["name"],
+            kids: this.createFromValues(source["kids"], Dummy),
+        } as Parent;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.kids = this.convertValues(source["kids"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+
+	` + strings.ReplaceAll(tsCreateFromValuesFunc, "classs.createFrom(", "classs.fromJSON(") + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Parent.fromJSON({kids: [{something: "bbb"}]}).kids[0].something === "bbb"`,
+	})
+
+	// Extended (abstract) classes, the nested ones are created with fromJSON() in the constructor, too:
+	type Child struct {
+		Dummy
+		Age  int     `json:"age"`
+		Kids []Dummy `json:"kids"`
+	}
+	converter = New().
+		Add(Child{}).
+		WithInheritance(true).
+		WithCreateFromMethodName("fromJSON").
+		WithLiteralCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithClassKeyword("abstract class").
+		WithBackupDir("")
+	desiredResult = `export abstract class Dummy {
+    something: string;
+
+    static fromJSON(source: any = {}): Dummy {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            something: source["something"],
+        } as Dummy;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export abstract class Child extends Dummy {
+    age: number;
+    kids: Dummy[];
+
+    static fromJSON(source: any = {}): Child {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            ...Dummy.fromJSON(source),
+            age: source["age"],
+            kids: this.createFromValues(source["kids"], Dummy),
+        } as Child;
+    }
+
+    constructor(source: any = {}) {
+        super(source);
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.age = source["age"];
+        this.kids = this.convertValues(source["kids"], Dummy);
+    }
+
+	` + strings.ReplaceAll(tsConvertValuesFunc, "new classs(a)", "classs.fromJSON(a)") + `
+
+	` + strings.ReplaceAll(tsCreateFromValuesFunc, "classs.createFrom(", "classs.fromJSON(") + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Child.fromJSON({age: 1, kids: [{something: "a"}]}).kids[0].something === "a"`,
+	})
+
+	// Abstract classes can't be instantiated:
+	for _, converter := range []*TypeScriptify{
+		New().Add(Child{}).WithClassKeyword("abstract class").WithBackupDir(""),
+		New().Add(Child{}).WithClassKeyword("abstract class").WithCreateFromMethod(true).WithBackupDir(""),
+		New().Add(Child{}).WithClassKeyword("abstract class").WithCreateFromMethod(true).WithLiteralCreateFrom(true).WithClone(true).WithBackupDir(""),
+	} {
+		_, err := converter.Convert(nil)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), `"abstract class" can't be instantiated`)
+	}
+}
+
+func TestLiteralCreateFrom(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Name  string           `json:"name"`
+		Child *Dummy           `json:"child"`
+		Kids  []Dummy          `json:"kids"`
+		Named map[string]Dummy `json:"named"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithLiteralCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithConstructor(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}): Dummy {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            something: source["something"],
+        } as Dummy;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    name: string;
+    child?: Dummy | null;
+    kids: Dummy[];
+    named: {[key: string]: Dummy};
+
+    static createFrom(source: any = {}): Parent {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            name: source["name"],
+            child: this.createFromValues(source["child"], Dummy),
+            kids: this.createFromValues(source["kids"], Dummy),
+            named: this.createFromValues(source["named"], Dummy, true),
+        } as Parent;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.child = this.convertValues(source["child"], Dummy);
+        this.kids = this.convertValues(source["kids"], Dummy);
+        this.named = this.convertValues(source["named"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+
+	` + tsCreateFromValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`!(Parent.createFrom({name: "aaa"}) instanceof Parent)`,
+		`Parent.createFrom({name: "aaa"}).name === "aaa"`,
+		`Parent.createFrom({kids: [{something: "bbb"}]}).kids[0].something === "bbb"`,
+		`!(Parent.createFrom({kids: [{something: "bbb"}]}).kids[0] instanceof Dummy)`,
+		// The source isn't mutated:
+		`(() => { const a = {something: "x"}; const source = {named: {a}}; const parent = Parent.createFrom(source); return source.named.a === a && parent.named["a"].something === "x"; })()`,
+	})
+}
+
+func TestTimeAsDate(t *testing.T) {
+	t.Parallel()
+	type Event struct {
+		Start     time.Time     `json:"start"`
+		End       *time.Time    `json:"end"`
+		Reminders []time.Time   `json:"reminders"`
+		Slots     [][]time.Time `json:"slots"`
+		Custom    time.Time     `json:"custom" ts_type:"string"`
+	}
+
+	converter := New().
+		Add(Event{}).
+		WithTimeAsDate(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Event {
+    start: Date;
+    end?: Date;
+    reminders: Date[];
+    slots: Date[][];
+    custom: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.start = new Date(source["start"]);
+        this.end = source["end"] ? new Date(source["end"]) : source["end"];
+        this.reminders = source["reminders"] ? source["reminders"].map((v1: any) => new Date(v1)) : source["reminders"];
+        this.slots = source["slots"] ? source["slots"].map((v1: any) => v1 ? v1.map((v2: any) => new Date(v2)) : v1) : source["slots"];
+        this.custom = source["custom"];
+    }
+}`
+	tm := time.Date(2020, 10, 9, 8, 9, 0, 0, time.UTC)
+	jsn := jsonizeOrPanic(Event{Start: tm, End: &tm, Reminders: []time.Time{tm}, Slots: [][]time.Time{{tm}}})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Event(` + jsn + `).start instanceof Date`,
+		`new Event(` + jsn + `).end instanceof Date`,
+		`new Event(` + jsn + `).reminders[0].toJSON() === "2020-10-09T08:09:00.000Z"`,
+		`new Event(` + jsn + `).slots[0][0] instanceof Date`,
+		`new Event({}).end === undefined`,
+	})
+}
+
+func TestStripSuffix(t *testing.T) {
+	t.Parallel()
+	type AddressDTO struct {
+		City string `json:"city"`
+	}
+	type UserDTO struct {
+		Name      string       `json:"name"`
+		Addresses []AddressDTO `json:"addresses"`
+	}
+
+	converter := New().
+		Add(UserDTO{}).
+		WithStripSuffix("DTO").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    city: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.city = source["city"];
+    }
+}
+export class User {
+    name: string;
+    addresses: Address[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.addresses = this.convertValues(source["addresses"], Address);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new User({addresses: [{city: "aaa"}]}).addresses[0] instanceof Address`,
+	})
+}
+
+func TestInterfaceIgnoresCreateFrom(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Person{}).
+		WithInterface(true).
+		WithCreateFromMethod(true).
+		WithConstructor(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Dummy {
+    something: string;
+}
+export interface Address {
+    duration: number;
+    text?: string;
+}
+export interface Person {
+    name: string;
+    nicknames: string[];
+    addresses: Address[];
+    address?: Address | null;
+    metadata: {[key:string]:string};
+    friends: Person[];
+    a: Dummy;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestLooseNullability(t *testing.T) {
+	t.Parallel()
+	type Loose struct {
+		Name  string            `json:"name"`
+		Age   int               `json:"age" ts_default:"18"`
+		Admin bool              `json:"admin"`
+		Tags  []string          `json:"tags"`
+		Attrs map[string]string `json:"attrs"`
+		Dummy Dummy             `json:"dummy"`
+		Note  *string           `json:"note"`
+	}
+
+	converter := New().
+		Add(Loose{}).
+		WithLooseNullability(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something?: string = "";
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"] ?? "";
+    }
+}
+export class Loose {
+    name?: string = "";
+    age?: number = 18;
+    admin?: boolean = false;
+    tags?: string[] = [];
+    attrs?: {[key: string]: string} = {};
+    dummy?: Dummy;
+    note?: string;
+
+    static createFrom(source: any = {}) {
+        return new Loose(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"] ?? "";
+        this.age = source["age"] ?? 18;
+        this.admin = source["admin"] ?? false;
+        this.tags = source["tags"] ?? [];
+        this.attrs = source["attrs"] ?? {};
+        this.dummy = this.convertValues(source["dummy"], Dummy);
+        this.note = source["note"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Loose.createFrom({}).name === ""`,
+		`Loose.createFrom({}).age === 18`,
+		`Loose.createFrom({"admin": null}).admin === false`,
+		`Loose.createFrom({}).tags?.length === 0`,
+		`Loose.createFrom({}).dummy === undefined`,
+		`Loose.createFrom({}).note === undefined`,
+		`Loose.createFrom({"name": "x", "dummy": {}}).dummy?.something === ""`,
+	})
+}
+
+func TestPrivateFields(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		Owner   string  `json:"owner"`
+		Balance float64 `json:"balance" ts_readonly:"true"`
+		Note    *string `json:"note"`
+	}
+
+	converter := New().
+		Add(Account{}).
+		WithPrivateFields(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Account {
+    #owner: string;
+    readonly #balance: number;
+    #note: string | undefined;
+
+    get owner(): string {
+        return this.#owner;
+    }
+
+    set owner(value: string) {
+        this.#owner = value;
+    }
+
+    get balance(): number {
+        return this.#balance;
+    }
+
+    get note(): string | undefined {
+        return this.#note;
+    }
+
+    set note(value: string | undefined) {
+        this.#note = value;
+    }
+
+    static createFrom(source: any = {}) {
+        return new Account(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.#owner = source["owner"];
+        this.#balance = source["balance"];
+        this.#note = source["note"];
+    }
+
+    toJSON(): any {
+        return {
+            "owner": this.owner,
+            "balance": this.balance,
+            "note": this.note,
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Account.createFrom({"owner": "me", "balance": 10}).balance === 10`,
+		`Object.keys(Account.createFrom({"owner": "me", "balance": 10})).length === 0`,
+		`JSON.stringify(Account.createFrom({"owner": "me", "balance": 10})) === '{"owner":"me","balance":10}'`,
+		`(() => { const a = Account.createFrom({"owner": "me"}); a.owner = "you"; return a.owner === "you"; })()`,
+	})
+
+	// Object literals can't have private fields:
+	_, err := New().
+		Add(Account{}).
+		WithPrivateFields(true).
+		WithCreateFromMethod(true).
+		WithLiteralCreateFrom(true).
+		WithBackupDir("").
+		Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "private fields can't be used with a literal createFrom (object literals can't have private fields)", err.Error())
+
+	// Readonly private fields have no setters for fromPartial():
+	_, err = New().
+		Add(Account{}).
+		WithPrivateFields(true).
+		WithFromPartial(true).
+		WithBackupDir("").
+		Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Account.fromPartial() can't set the readonly private fields (balance)", err.Error())
+}
+
+func TestComparators(t *testing.T) {
+	t.Parallel()
+	type Product struct {
+		Name    string   `json:"name"`
+		Price   float64  `json:"price"`
+		InStock *bool    `json:"in_stock"`
+		Tags    []string `json:"tags"`
+	}
+
+	converter := New().
+		Add(Product{}).
+		WithComparators(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Product {
+    name: string;
+    price: number;
+    in_stock?: boolean;
+    tags: string[];
+}
+export function compareProductBy<K extends "name" | "price" | "in_stock">(key: K): (a: Product, b: Product) => number {
+    return (a: Product, b: Product) => {
+        const x: any = a[key], y: any = b[key];
+        if (x === y) return 0;
+        if (x === undefined || x === null) return -1;
+        if (y === undefined || y === null) return 1;
+        return x < y ? -1 : 1;
+    };
+}`
+	products := `[{name: "b", price: 2, tags: []}, {name: "a", price: 3, tags: [], in_stock: true}, {name: "c", price: 1, tags: []}]`
+	testConverter(t, converter, true, desiredResult, []string{
+		products + `.sort(compareProductBy("name")).map(p => p.name).join() === "a,b,c"`,
+		products + `.sort(compareProductBy("price")).map(p => p.name).join() === "c,b,a"`,
+		products + `.sort(compareProductBy("in_stock"))[2].name === "a"`,
+	})
+}
+
+func TestFlattenTag(t *testing.T) {
+	t.Parallel()
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+		UpdatedBy string `json:"updated_by"`
+	}
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Customer struct {
+		Name    string   `json:"name"`
+		Audit   Audit    `json:"audit" ts_flatten:"true"`
+		Address *Address `json:"address"`
+	}
+
+	converter := New().
+		Add(Customer{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Address {
+    city: string;
+}
+export interface Customer {
+    name: string;
+    created_by: string;
+    updated_by: string;
+    address?: Address | null;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestInlineMap(t *testing.T) {
+	t.Parallel()
+	type Flexible struct {
+		Name  string                 `json:"name"`
+		Age   int                    `json:"age,omitempty"`
+		Extra map[string]interface{} `json:"-" ts_inline:"true"`
+		Cache string                 `json:"-"`
+	}
+
+	converter := New().
+		Add(Flexible{}).
+		WithToJSON(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Flexible {
+    name: string;
+    age?: number;
+    [key: string]: any;
+
+    static createFrom(source: any = {}) {
+        return new Flexible(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.age = source["age"];
+        for (const key of Object.keys(source)) if (!["name", "age"].includes(key)) this[key] = source[key];
+    }
+
+    toJSON(): any {
+        return {
+            "name": this.name,
+            "age": this.age,
+            ...Object.keys(this).filter(key => !["name", "age"].includes(key)).reduce((m: any, key: string) => { m[key] = this[key]; return m; }, {}),
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Flexible.createFrom({"name": "x", "color": "red"})["color"] === "red"`,
+		`Flexible.createFrom({"name": "x", "color": "red"}).name === "x"`,
+		`JSON.stringify(Flexible.createFrom({"name": "x", "size": 2})) === '{"name":"x","size":2}'`,
+	})
+
+	literal, err := New().Add(Flexible{}).WithCreateFromMethod(true).WithLiteralCreateFrom(true).Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, literal, `...Object.keys(source).filter(key => !["name", "age"].includes(key)).reduce((m: any, key: string) => { m[key] = source[key]; return m; }, {}),`)
+
+	type TwoInline struct {
+		Extra map[string]interface{} `json:"-" ts_inline:"true"`
+		Other map[string]interface{} `json:"-" ts_inline:"true"`
+	}
+	_, err = New().Add(TwoInline{}).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "TwoInline has more than one ts_inline map (Extra and Other)", err.Error())
+
+	type TypedInline struct {
+		Name  string         `json:"name"`
+		Extra map[string]int `json:"-" ts_inline:"true"`
+	}
+	_, err = New().Add(TypedInline{}).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ts_inline field Extra must be a map of interface{} values")
+}
+
+func TestPointerToScalarFields(t *testing.T) {
+	t.Parallel()
+	type Scalars struct {
+		Count   *int    `json:"count"`
+		Name    *string `json:"name"`
+		Enabled *bool   `json:"enabled"`
+	}
+
+	converter := New().
+		Add(Scalars{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Scalars {
+    count?: number;
+    name?: string;
+    enabled?: boolean;
+
+    static createFrom(source: any = {}) {
+        return new Scalars(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.count = source["count"];
+        this.name = source["name"];
+        this.enabled = source["enabled"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Scalars.createFrom({"count": 7, "name": "x", "enabled": false}).count === 7`,
+		`Scalars.createFrom({"count": null, "name": null, "enabled": null}).name === null`,
+		`Scalars.createFrom({}).enabled === undefined`,
+	})
+}
+
+func TestOmitemptyAndPointerOptional(t *testing.T) {
+	t.Parallel()
+	type Optionals struct {
+		Name      string  `json:"omitempty"`
+		Nickname  string  `json:"nickname,string,omitempty"`
+		Age       *int    `json:"age"`
+		Email     *string `json:"email,omitempty"`
+		Unnamed   string  `json:",omitempty"`
+		Addresses *Dummy  `json:"dummy,omitempty"`
+	}
+
+	converter := New().
+		Add(Optionals{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Optionals {
+    omitempty: string;
+    nickname?: string;
+    age?: number;
+    email?: string;
+    dummy?: Dummy | null;
+
+    static createFrom(source: any = {}) {
+        return new Optionals(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.omitempty = source["omitempty"];
+        this.nickname = source["nickname"];
+        this.age = source["age"];
+        this.email = source["email"];
+        this.dummy = this.convertValues(source["dummy"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Optionals({}).dummy === undefined`,
+		`new Optionals({"dummy": {"something": "x"}}).dummy instanceof Dummy`,
+	})
+}
+
+func TestOmitemptyAndStringOptions(t *testing.T) {
+	t.Parallel()
+	type Combined struct {
+		ID      int64   `json:"id,omitempty,string"`
+		Count   int     `json:"count,string,omitempty"`
+		Price   *string `json:"price,omitempty,string"`
+		Enabled *bool   `json:"enabled,string,omitempty"`
+		Ratio   float64 `json:"ratio,string"`
+	}
+
+	converter := New().
+		Add(Combined{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Combined {
+    id?: string;
+    count?: string;
+    price?: string;
+    enabled?: string;
+    ratio: string;
+
+    static createFrom(source: any = {}) {
+        return new Combined(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.count = source["count"];
+        this.price = source["price"];
+        this.enabled = source["enabled"];
+        this.ratio = source["ratio"];
+    }
+}`
+	jsn := jsonizeOrPanic(Combined{ID: 12345678901234567, Ratio: 0.5})
+	testConverter(t, converter, true, desiredResult, []string{
+		`Combined.createFrom(` + jsn + `).id === "12345678901234567"`,
+		`Combined.createFrom(` + jsn + `).count === undefined`,
+		`Combined.createFrom(` + jsn + `).ratio === "0.5"`,
+	})
+}
+
+func TestImportedTypes(t *testing.T) {
+	t.Parallel()
+	type Place struct {
+		Main   Address   `json:"main"`
+		Others []Address `json:"others"`
+	}
+
+	converter := New().
+		Add(Place{}).
+		AddImportedType(Address{}).
+		WithImportPath("@myorg/types/__TYPE__").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `import { Address } from '@myorg/types/Address';
+
+export class Place {
+    main: Address;
+    others: Address[];
+}`, typeScriptCode)
+}
+
+func TestTypeImports(t *testing.T) {
+	t.Parallel()
+	type Place struct {
+		Main Address `json:"main"`
+	}
+	type Route struct {
+		From Address `json:"from"`
+	}
+
+	converter := New().
+		Add(Place{}).
+		AddImportedType(Address{}).
+		WithInterface(true).
+		WithTypeImports(true).
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `import type { Address } from './Address';
+
+export interface Place {
+    main: Address;
+}`, typeScriptCode)
+
+	// Classes created in the constructor must be imported as values:
+	converter = New().
+		Add(Route{}).
+		AddImportedType(Address{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithTypeImports(true).
+		WithBackupDir("")
+
+	typeScriptCode, err = converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(typeScriptCode, "import { Address } from './Address';\n"), typeScriptCode)
+
+	// ...but not in a class without a constructor (or createFrom()):
+	converter = New().
+		Add(Route{}).
+		AddImportedType(Address{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithTypeImports(true).
+		WithBackupDir("")
+
+	typeScriptCode, err = converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(typeScriptCode, "import type { Address } from './Address';\n"), typeScriptCode)
+}
+
+func TestCollidingPropertyNames(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		UserID   string `json:"user_id"`
+		LegacyID string `json:"userId"`
+		Name     string `json:"name"`
+	}
+	snakeToCamel := func(name string) string {
+		return strings.ReplaceAll(name, "_id", "Id")
+	}
+
+	_, err := New().
+		Add(Account{}).
+		WithFieldNameTransform(snakeToCamel).
+		WithBackupDir("").
+		Convert(nil)
+	assert.EqualError(t, err, "Account.UserID (user_id) and Account.LegacyID (userId) are both converted to the property userId")
+
+	converter := New().
+		Add(Account{}).
+		WithFieldNameTransform(snakeToCamel).
+		WithSuffixCollidingNames(true).
+		WithToJSON(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Account {
+    userId: string;
+    userId2: string;
+    name: string;
+
+    static createFrom(source: any = {}) {
+        return new Account(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.userId = source["user_id"];
+        this.userId2 = source["userId"];
+        this.name = source["name"];
+    }
+
+    toJSON(): any {
+        return {
+            "user_id": this.userId,
+            "userId": this.userId2,
+            "name": this.name,
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`JSON.stringify(Account.createFrom({"user_id": "a", "userId": "b", "name": "c"})) === '{"user_id":"a","userId":"b","name":"c"}'`,
+	})
+}
+
+func TestToJSONWithFieldNameTransform(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		FirstName string `json:"first_name"`
+		Age       int    `json:"age,omitempty"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		WithFieldNameTransform(func(name string) string {
+			return strings.ReplaceAll(name, "_name", "Name")
+		}).
+		WithToJSON(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Person {
+    firstName: string;
+    age?: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.firstName = source["first_name"];
+        this.age = source["age"];
+    }
+
+    toJSON(): any {
+        return {
+            "first_name": this.firstName,
+            "age": this.age,
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Person({"first_name": "Jane"}).firstName == "Jane"`,
+		`JSON.stringify(new Person({"first_name": "Jane", "age": 7})) == '{"first_name":"Jane","age":7}'`,
+	})
+}
+
+func TestFieldsOrder(t *testing.T) {
+	t.Parallel()
+	type Ordered struct {
+		First  string `json:"first"`
+		Second Dummy  `json:"second"`
+		Third  int    `json:"third"`
+	}
+
+	converter := New().
+		Add(Ordered{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+}
+export class Ordered {
+    first: string;
+    second: Dummy;
+    third: number;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestAddTypeMapping(t *testing.T) {
+	t.Parallel()
+	type Big struct {
+		ID      int64            `json:"id"`
+		IDs     []int64          `json:"ids"`
+		ByName  map[string]int64 `json:"by_name"`
+		Count   int              `json:"count"`
+		Payload interface{}      `json:"payload"`
+	}
+
+	converter := New().
+		Add(Big{}).
+		AddTypeMapping(reflect.Int64, "string").
+		AddTypeMapping(reflect.Interface, "unknown").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Big {
+    id: string;
+    ids: string[];
+    by_name: {[key: string]: string};
+    count: number;
+    payload: unknown;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestStringUnionFromMapIsSorted(t *testing.T) {
+	t.Parallel()
+	statuses := map[string]int{"deleted": 3, "active": 1, "pending": 2, "archived": 4}
+
+	converter := New().
+		AddStringUnion("Status", statuses).
+		AddStringUnion("Color", []string{"red", "green", "blue"}).
+		WithBackupDir("")
+
+	desiredResult := `export type Status = "active" | "archived" | "deleted" | "pending";
+export type Color = "red" | "green" | "blue";`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	for i := 0; i < 10; i++ {
+		typeScriptCode, err := New().AddStringUnion("Status", statuses).Convert(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "\nexport type Status = \"active\" | \"archived\" | \"deleted\" | \"pending\";", typeScriptCode)
+	}
+}
+
+func TestCustomImportsInSplitFiles(t *testing.T) {
+	t.Parallel()
+	type Invoice struct {
+		Total string `json:"total" ts_type:"Decimal" ts_transform:"parseDecimal(__VALUE__)"`
+	}
+
+	converter := New().
+		Add(Invoice{}).
+		AddImport("import { Decimal, parseDecimal } from './decimal';").
+		AddImport("import { Decimal, parseDecimal } from './decimal';").
+		WithBackupDir("")
+
+	types, converters, err := converter.ConvertSplit("./types")
+	assert.Nil(t, err)
+	assert.Equal(t, `import { Decimal, parseDecimal } from './decimal';
+
+export interface Invoice {
+    total: Decimal;
+}`, types)
+	assert.Equal(t, `import { Decimal, parseDecimal } from './decimal';
+import { Invoice } from './types';
+
+export function createInvoice(source: any = {}): Invoice {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return {
+        total: parseDecimal(source["total"]),
+    };
+}
+`, converters)
+}
+
+func TestConvertSplit(t *testing.T) {
+	t.Parallel()
+	type Place struct {
+		Name    string   `json:"name"`
+		Main    Dummy    `json:"main"`
+		Others  []Dummy  `json:"others"`
+		Details *Dummy   `json:"details"`
+		Tags    []string `json:"tags"`
+	}
+
+	converter := New().
+		Add(Place{}).
+		WithBackupDir("")
+
+	types, converters, err := converter.ConvertSplit("./types")
+	assert.Nil(t, err)
+	assert.Equal(t, `
+export interface Dummy {
+    something: string;
+}
+export interface Place {
+    name: string;
+    main: Dummy;
+    others: Dummy[];
+    details?: Dummy | null;
+    tags: string[];
+}`, types)
+	assert.Equal(t, `import { Dummy, Place } from './types';
+
+function convertValues(a: any, create: (source: any) => any, asMap: boolean = false): any {
+    if (!a) {
+        return a;
+    }
+    if (a.slice) {
+        return (a as any[]).map(elem => convertValues(elem, create, asMap));
+    } else if ("object" === typeof a) {
+        if (asMap) {
+            const result: any = {};
+            for (const key of Object.keys(a)) {
+                result[key] = convertValues(a[key], create);
+            }
+            return result;
+        }
+        return create(a);
+    }
+    return a;
+}
+
+export function createDummy(source: any = {}): Dummy {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return {
+        something: source["something"],
+    };
+}
+
+export function createPlace(source: any = {}): Place {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return {
+        name: source["name"],
+        main: convertValues(source["main"], createDummy),
+        others: convertValues(source["others"], createDummy),
+        details: convertValues(source["details"], createDummy),
+        tags: source["tags"],
+    };
+}
+`, converters)
+
+	// Both files together must compile:
+	testTypescriptExpression(t, true, types+"\n"+strings.SplitN(converters, "\n", 2)[1], []string{
+		`createPlace({"main": {"something": "x"}}).main.something == "x"`,
+		`createPlace({"others": [{"something": "y"}]}).others[0].something == "y"`,
+	})
+}
+
+func TestTimeType(t *testing.T) {
+	t.Parallel()
+	type Event struct {
+		Start time.Time   `json:"start"`
+		End   *time.Time  `json:"end"`
+		Dates []time.Time `json:"dates"`
+	}
+
+	converter := New().
+		Add(Event{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Event {
+    start: string;
+    end?: string;
+    dates: string[];
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	converter = New().
+		Add(Event{}).
+		WithTimeType("Date").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult = `export class Event {
+    start: Date;
+    end?: Date;
+    dates: Date[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.start = new Date(source["start"]);
+        this.end = source["end"] ? new Date(source["end"]) : source["end"];
+        this.dates = source["dates"] ? source["dates"].map((v1: any) => new Date(v1)) : source["dates"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Event({"start": "2020-10-09T08:09:00Z"}).start instanceof Date`,
+	})
+
+	// WithTimeAsDate() is the same as WithTimeType("Date"), the last one is used:
+	converter = New().
+		Add(Event{}).
+		WithTimeType("number").
+		WithTimeAsDate(true).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+	assert.Equal(t, "Date", converter.TimeType)
+	converter.WithTimeType("number")
+	desiredResult = `export class Event {
+    start: number;
+    end?: number;
+    dates: number[];
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	// The deprecated TimeAsDate field doesn't override an explicit TimeType:
+	converter.TimeAsDate = true
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestByteSlices(t *testing.T) {
+	t.Parallel()
+	type Blob struct {
+		Data     []byte            `json:"data"`
+		Chunks   [][]byte          `json:"chunks"`
+		ByName   map[string][]byte `json:"by_name"`
+		Checksum [4]byte           `json:"checksum"`
+	}
+
+	converter := New().
+		Add(Blob{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Blob {
+    data: string;
+    chunks: string[];
+    by_name: {[key: string]: string};
+    checksum: number[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.data = source["data"];
+        this.chunks = source["chunks"];
+        this.by_name = source["by_name"];
+        this.checksum = source["checksum"];
+    }
+}`
+	jsn := jsonizeOrPanic(Blob{Data: []byte("abc"), Chunks: [][]byte{[]byte("x")}})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Blob(` + jsonizeOrPanic(jsn) + `).data === "YWJj"`,
+		`new Blob(` + jsonizeOrPanic(jsn) + `).chunks[0] === "eA=="`,
+	})
+}
+
+func TestOnlyEmbeddedFields(t *testing.T) {
+	t.Parallel()
+	type Base struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type OnlyEmbedded struct {
+		Base
+	}
+
+	converter := New().
+		Add(OnlyEmbedded{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class OnlyEmbedded {
+    id: number;
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.name = source["name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new OnlyEmbedded(` + jsonizeOrPanic(jsonizeOrPanic(OnlyEmbedded{Base: Base{ID: 7}})) + `).id === 7`,
+	})
+}
+
+type BaseEntity struct {
+	ID int `json:"id"`
+}
+
+func TestInheritance(t *testing.T) {
+	t.Parallel()
+	type User struct {
+		BaseEntity
+		Name string `json:"name"`
+	}
+
+	converter := New().
+		Add(User{}).
+		WithInheritance(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class BaseEntity {
+    id: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+    }
+}
+export class User extends BaseEntity {
+    name: string;
+
+    constructor(source: any = {}) {
+        super(source);
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+}`
+	jsn := jsonizeOrPanic(User{BaseEntity: BaseEntity{ID: 5}, Name: "Jane"})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new User(` + jsonizeOrPanic(jsn) + `).id === 5`,
+		`new User(` + jsonizeOrPanic(jsn) + `).name === "Jane"`,
+		`new User(` + jsonizeOrPanic(jsn) + `) instanceof BaseEntity`,
+	})
+
+	converter = New().
+		Add(User{}).
+		WithInheritance(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult = `export interface BaseEntity {
+    id: number;
+}
+export interface User extends BaseEntity {
+    name: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestSourceType(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Address{}).
+		WithSourceType("Record<string, any>").
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    static createFrom(source: Record<string, any> = {}) {
+        return new Address(source);
+    }
+
+    constructor(source: Record<string, any> = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Address.createFrom({"duration": 1}).duration === 1`,
+	})
+}
+
+func TestTagComments(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		Email string `json:"email" validate:"required"`
+		Name  string `json:"name,omitempty"`
+	}
+
+	converter := New().
+		Add(Account{}).
+		WithTagComments(true).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Account {
+    // tag: json:"email" validate:"required"
+    email: string;
+    // tag: json:"name,omitempty"
+    name?: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestConvertToWriter(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	var buf bytes.Buffer
+	assert.Nil(t, converter.ConvertToWriter(&buf, nil))
+	assert.Equal(t, "/* Do not change, this code is generated from Golang structs */\n\n\nexport class Dummy {\n    something: string;\n}\n", buf.String())
+}
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+	convert := func(header string) string {
+		converter := New().
+			Add(Dummy{}).
+			WithHeader(header).
+			WithConstructor(false).
+			WithCreateFromMethod(false).
+			WithBackupDir("")
+		var buf bytes.Buffer
+		assert.Nil(t, converter.ConvertToWriter(&buf, nil))
+		return buf.String()
+	}
+
+	assert.Equal(t, "export class Dummy {\n    something: string;\n}\n", convert(""))
+	assert.Equal(t, "// @generated\n\n\nexport class Dummy {\n    something: string;\n}\n", convert("// @generated"))
+}
+
+func TestBlankLines(t *testing.T) {
+	t.Parallel()
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+		UpdatedBy string `json:"updated_by"`
+	}
+	type Document struct {
+		Title string `json:"title"`
+		Audit
+		Address Address `json:"address"`
+	}
+
+	converter := New().
+		Add(Document{}).
+		WithBlankLines(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Address {
+    duration: number;
+    text?: string;
+}
+
+export interface Document {
+    title: string;
+
+    // Promoted from Audit
+    created_by: string;
+    updated_by: string;
+
+    address: Address;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, typeScriptCode, "}\n\nexport interface Document {")
+}
+
+func TestLineEnding(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Dummy{}).
+		WithLineEnding("\r\n").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	var buf bytes.Buffer
+	assert.Nil(t, converter.ConvertToWriter(&buf, map[string]string{"Dummy": "    hello() {\r\n        return 1;\r\n    }"}))
+	assert.Equal(t, "/* Do not change, this code is generated from Golang structs */\r\n\r\n\r\nexport class Dummy {\r\n    something: string;\r\n    //[Dummy:]\r\n    hello() {\r\n        return 1;\r\n    }\r\n\r\n    //[end]\r\n}\r\n", buf.String())
+
+	// Custom code written with CRLF is read without the CRs:
+	customCode, err := ParseCustomCode(strings.NewReader(buf.String()))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Dummy": "    hello() {\n        return 1;\n    }"}, customCode)
+}
+
+func TestMapOfStructsIndexesFieldBeforeKey(t *testing.T) {
+	t.Parallel()
+	type Keyboard struct {
+		Keys map[string]Address `json:"keys"`
+	}
+
+	converter := New().
+		Add(Keyboard{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    static createFrom(source: any = {}) {
+        return new Address(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+}
+export class Keyboard {
+    keys: {[key: string]: Address};
+
+    static createFrom(source: any = {}) {
+        return new Keyboard(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.keys = this.convertValues(source["keys"], Address, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Keyboard.createFrom({"keys": {"a": {"text": "right"}}, "a": {"text": "wrong"}}).keys["a"].text === "right"`,
+		`Keyboard.createFrom({"keys": {"a": {"text": "right"}}}).keys["a"] instanceof Address`,
+		`Keyboard.createFrom({}).keys === undefined`,
+	})
+}
+
+type AnyValue interface{}
+type JSONValue = interface{}
+
+func TestNamedInterfaceFields(t *testing.T) {
+	t.Parallel()
+	type Payload struct {
+		Value  AnyValue            `json:"value"`
+		Values []AnyValue          `json:"values"`
+		ByKey  map[string]AnyValue `json:"by_key"`
+		Raw    JSONValue           `json:"raw"`
+	}
+
+	converter := New().
+		Add(Payload{}).
+		WithNamedScalars(true).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Payload {
+    value: any;
+    values: any[];
+    by_key: {[key: string]: any};
+    raw: any;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	converter.AddTypeMapping(reflect.Interface, "unknown")
+	desiredResult = `export class Payload {
+    value: unknown;
+    values: unknown[];
+    by_key: {[key: string]: unknown};
+    raw: unknown;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestSliceAndMapOfStructPointers(t *testing.T) {
+	t.Parallel()
+	type Registry struct {
+		List  []*Dummy          `json:"list"`
+		ByKey map[string]*Dummy `json:"by_key"`
+	}
+
+	converter := New().
+		Add(Registry{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Registry {
+    list: Dummy[];
+    by_key: {[key: string]: Dummy};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.list = this.convertValues(source["list"], Dummy);
+        this.by_key = this.convertValues(source["by_key"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn := jsonizeOrPanic(Registry{List: []*Dummy{{Something: "a"}}, ByKey: map[string]*Dummy{"k": {Something: "b"}}})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Registry(` + jsonizeOrPanic(jsn) + `).list[0] instanceof Dummy`,
+		`new Registry(` + jsonizeOrPanic(jsn) + `).by_key["k"].something === "b"`,
+	})
+}
+
+func TestTSNameTag(t *testing.T) {
+	t.Parallel()
+	type Legacy struct {
+		UserName string `json:"userName" ts_name:"UserName"`
+		Email    string `json:"email"`
+	}
+
+	converter := New().
+		Add(Legacy{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Legacy {
+    UserName: string;
+    email: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.UserName = source["userName"];
+        this.email = source["email"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Legacy({"userName": "jane"}).UserName === "jane"`,
+	})
+}
+
+func TestReadonlyContainers(t *testing.T) {
+	t.Parallel()
+	type Frozen struct {
+		Name    string             `json:"name"`
+		Tags    []string           `json:"tags"`
+		Matrix  [][]int            `json:"matrix"`
+		Dummies []Dummy            `json:"dummies"`
+		ByKey   map[string]Dummy   `json:"by_key"`
+		Lists   map[string][]Dummy `json:"lists"`
+	}
+
+	converter := New().
+		Add(Frozen{}).
+		WithReadonlyFields(true).
+		WithReadonlyContainers(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Dummy {
+    readonly something: string;
+}
+export interface Frozen {
+    readonly name: string;
+    readonly tags: ReadonlyArray<string>;
+    readonly matrix: ReadonlyArray<ReadonlyArray<number>>;
+    readonly dummies: ReadonlyArray<Dummy>;
+    readonly by_key: Readonly<{[key: string]: Dummy}>;
+    readonly lists: Readonly<{[key: string]: ReadonlyArray<Dummy>}>;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestNestedSlices(t *testing.T) {
+	t.Parallel()
+	type Grid struct {
+		Numbers [][]int            `json:"numbers"`
+		Dummies [][]*Dummy         `json:"dummies"`
+		Maps    []map[string]Dummy `json:"maps"`
+	}
+
+	converter := New().
+		Add(Grid{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Grid {
+    numbers: number[][];
+    dummies: Dummy[][];
+    maps: {[key: string]: Dummy}[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.numbers = source["numbers"];
+        this.dummies = this.convertValues(source["dummies"], Dummy);
+        this.maps = this.convertValues(source["maps"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn := jsonizeOrPanic(Grid{
+		Numbers: [][]int{{1, 2}, {3}},
+		Dummies: [][]*Dummy{{{Something: "a"}}},
+		Maps:    []map[string]Dummy{{"k": {Something: "b"}}},
+	})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Grid(` + jsonizeOrPanic(jsn) + `).numbers[1][0] === 3`,
+		`new Grid(` + jsonizeOrPanic(jsn) + `).dummies[0][0] instanceof Dummy`,
+		`new Grid(` + jsonizeOrPanic(jsn) + `).maps[0]["k"] instanceof Dummy`,
+	})
+}
+
+func TestFromPartial(t *testing.T) {
+	t.Parallel()
+	type Fixture struct {
+		Name    string            `json:"name"`
+		Count   int               `json:"count"`
+		Active  bool              `json:"active"`
+		Tags    []string          `json:"tags"`
+		Meta    map[string]string `json:"meta"`
+		Dummy   Dummy             `json:"dummy"`
+		Comment string            `json:"comment,omitempty"`
+	}
+
+	converter := New().
+		Add(Fixture{}).
+		WithFromPartial(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static fromPartial(source: Partial<Dummy> = {}): Dummy {
+        return Object.assign(new Dummy({
+            "something": "",
+        }), source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Fixture {
+    name: string;
+    count: number;
+    active: boolean;
+    tags: string[];
+    meta: {[key: string]: string};
+    dummy: Dummy;
+    comment?: string;
+
+    static fromPartial(source: Partial<Fixture> = {}): Fixture {
+        return Object.assign(new Fixture({
+            "name": "",
+            "count": 0,
+            "active": false,
+            "tags": [],
+            "meta": {},
+            "dummy": {},
+        }), source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.count = source["count"];
+        this.active = source["active"];
+        this.tags = source["tags"];
+        this.meta = source["meta"];
+        this.dummy = this.convertValues(source["dummy"], Dummy);
+        this.comment = source["comment"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Fixture.fromPartial({name: "x"}).name === "x"`,
+		`Fixture.fromPartial({name: "x"}).count === 0`,
+		`Fixture.fromPartial({}).tags.length === 0`,
+		`Fixture.fromPartial({}).dummy instanceof Dummy`,
+		`Fixture.fromPartial({}).comment === undefined`,
+		`Fixture.fromPartial({}) instanceof Fixture`,
+	})
+}
+
+func TestConvertTypes(t *testing.T) {
+	t.Parallel()
+	type Place struct {
+		Name    string  `json:"name"`
+		Dummy   Dummy   `json:"dummy"`
+		Weekday Weekday `json:"weekday"`
+	}
+
+	converter := New().
+		Add(Place{}).
+		AddEnum(allWeekdaysV1).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	types, err := converter.ConvertTypes(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(types))
+	assert.Equal(t, "export class Dummy {\n    something: string;\n}", types["Dummy"])
+	assert.Equal(t, "export class Place {\n    name: string;\n    dummy: Dummy;\n    weekday: Weekday;\n}", types["Place"])
+	assert.Contains(t, types["Weekday"], "export enum Weekday {")
+}
+
+func TestEnumKeyedMap(t *testing.T) {
+	t.Parallel()
+	type Schedule struct {
+		Tasks map[Weekday]Dummy  `json:"tasks"`
+		Days  map[string]Weekday `json:"days"`
+	}
+
+	converter := New().
+		Add(Schedule{}).
+		AddEnum(allWeekdaysV1).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export enum Weekday {
+    SUNDAY = 0,
+    MONDAY = 1,
+    TUESDAY = 2,
+    WEDNESDAY = 3,
+    THURSDAY = 4,
+    FRIDAY = 5,
+    SATURDAY = 6,
+}
+export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Schedule {
+    tasks: Partial<Record<Weekday, Dummy>>;
+    days: {[key: string]: Weekday};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.tasks = this.convertValues(source["tasks"], Dummy, true);
+        this.days = source["days"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn := jsonizeOrPanic(Schedule{Tasks: map[Weekday]Dummy{Monday: {Something: "work"}}})
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Schedule(` + jsonizeOrPanic(jsn) + `).tasks[Weekday.MONDAY]?.something === "work"`,
+	})
+}
+
+func TestStrictNames(t *testing.T) {
+	t.Parallel()
+	type Outer struct {
+		Name  string `json:"name"`
+		Inner struct {
+			Value int `json:"value"`
+		} `json:"inner"`
+	}
+
+	_, err := New().Add(Outer{}).WithStrictNames(true).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Outer.Inner")
+
+	_, err = New().Add(Person{}).WithStrictNames(true).Convert(nil)
+	assert.Nil(t, err)
+}
+
+func TestErrorOnEmpty(t *testing.T) {
+	t.Parallel()
+	type Hollow struct {
+		name  string
+		Value int `json:"-"`
+	}
+	type Outer struct {
+		Name   string `json:"name"`
+		Hollow Hollow `json:"hollow"`
+	}
+
+	// Permissive by default:
+	code, err := New().Add(Hollow{}).WithInterface(true).Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, code, "export interface Hollow {")
+
+	_, err = New().Add(Hollow{}).WithErrorOnEmpty(true).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "github.com/tkrajina/typescriptify-golang-structs/typescriptify.Hollow has no exported fields with JSON names", err.Error())
+
+	_, err = New().Add(Outer{}).WithErrorOnEmpty(true).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "(in Outer.Hollow)")
+
+	_, err = New().Add(Person{}).WithErrorOnEmpty(true).Convert(nil)
+	assert.Nil(t, err)
+}
+
+type TextKey struct {
+	A, B string
+}
+
+func (k TextKey) MarshalText() ([]byte, error) {
+	return []byte(k.A + "-" + k.B), nil
+}
+
+type PtrTextKey struct {
+	A, B string
+}
+
+func (k *PtrTextKey) MarshalText() ([]byte, error) {
+	return []byte(k.A + "-" + k.B), nil
+}
+
+func TestTextMarshalerStructMapKeys(t *testing.T) {
+	t.Parallel()
+	type Grid struct {
+		Cells map[TextKey]Dummy `json:"cells"`
+	}
+
+	converter := New().
+		Add(Grid{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Grid {
+    cells: {[key: string]: Dummy};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.cells = this.convertValues(source["cells"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn, err := json.Marshal(Grid{Cells: map[TextKey]Dummy{{A: "a", B: "b"}: {Something: "x"}}})
+	assert.Nil(t, err)
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Grid(` + string(jsn) + `).cells["a-b"] instanceof Dummy`,
+		`new Grid(` + string(jsn) + `).cells["a-b"].something === "x"`,
+	})
+
+	// encoding/json doesn't use MarshalText() with a pointer receiver for map keys:
+	type PtrKeys struct {
+		ByKey map[PtrTextKey]string `json:"by_key"`
+	}
+	_, err = json.Marshal(PtrKeys{ByKey: map[PtrTextKey]string{{}: "x"}})
+	assert.NotNil(t, err)
+	_, err = New().Add(PtrKeys{}).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unsupported map key type")
+}
+
+func TestByteArraysAsStrings(t *testing.T) {
+	t.Parallel()
+	type Record struct {
+		ID       [16]byte  `json:"id"`
+		Checksum *[4]byte  `json:"checksum"`
+		Values   [3]uint16 `json:"values"`
+	}
+
+	converter := New().
+		Add(Record{}).
+		WithInterface(true).
+		WithBackupDir("")
+	testConverter(t, converter, true, `export interface Record {
+    id: number[];
+    checksum?: number[] | null;
+    values: number[];
+}`, nil)
+
+	converter = New().
+		Add(Record{}).
+		WithByteArraysAsStrings(true).
+		WithInterface(true).
+		WithBackupDir("")
+	testConverter(t, converter, true, `export interface Record {
+    id: string;
+    checksum?: string | null;
+    values: number[];
+}`, nil)
+}
+
+func TestFixedSizeArrays(t *testing.T) {
+	t.Parallel()
+	type Segment struct {
+		Coords  [3]int      `json:"coords"`
+		Ends    [2]Dummy    `json:"ends"`
+		Grid    [2][2]int   `json:"grid"`
+		Corners [][2]string `json:"corners"`
+	}
+
+	converter := New().
+		Add(Segment{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Segment {
+    coords: number[];
+    ends: Dummy[];
+    grid: number[][];
+    corners: string[][];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.coords = source["coords"];
+        this.ends = this.convertValues(source["ends"], Dummy);
+        this.grid = source["grid"];
+        this.corners = source["corners"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	jsn, err := json.Marshal(Segment{Coords: [3]int{1, 2, 3}, Ends: [2]Dummy{{Something: "a"}, {Something: "b"}}})
+	assert.Nil(t, err)
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Segment(` + string(jsn) + `).coords[2] === 3`,
+		`new Segment(` + string(jsn) + `).ends[1] instanceof Dummy`,
+	})
+
+	converter = New().
+		Add(Segment{}).
+		WithArrayTuples(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult = `export interface Dummy {
+    something: string;
+}
+export interface Segment {
+    coords: [number, number, number];
+    ends: [Dummy, Dummy];
+    grid: [[number, number], [number, number]];
+    corners: [string, string][];
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestIntegerMapKeys(t *testing.T) {
+	t.Parallel()
+	type Indexed struct {
+		ByID   map[int]Dummy      `json:"by_id"`
+		ByUint map[uint64]string  `json:"by_uint"`
+		ByText map[TextKey]string `json:"by_text"`
+	}
+
+	converter := New().
+		Add(Indexed{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+}
+export class Indexed {
+    by_id: {[key: number]: Dummy};
+    by_uint: {[key: number]: string};
+    by_text: {[key: string]: string};
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	type StructKeys struct {
+		ByDummy map[Dummy]string `json:"by_dummy"`
+	}
+	_, err := New().Add(StructKeys{}).Convert(nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unsupported map key type")
+}
+
+func TestFieldNameFunc(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name" ts_name:"surname"`
+	}
+
+	// ts_name has precedence over FieldNameFunc, and FieldNameFunc over FieldNameTransform:
+	converter := New().
+		Add(Person{}).
+		WithFieldNameFunc(func(jsonName string, field reflect.StructField) string {
+			return strings.ToLower(field.Name[:1]) + field.Name[1:]
+		}).
+		WithFieldNameTransform(strings.ToUpper).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Person {
+    firstName: string;
+    surname: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.firstName = source["first_name"];
+        this.surname = source["last_name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Person({"first_name": "Jane"}).firstName === "Jane"`,
+	})
+}
+
+type Shape interface{}
+
+func TestSliceOfSumType(t *testing.T) {
+	t.Parallel()
+	type Drawing struct {
+		Shapes []Shape `json:"shapes"`
+		Main   Shape   `json:"main"`
+	}
+
+	converter := New().
+		Add(Drawing{}).
+		AddUnionWithDiscriminator(reflect.TypeOf((*Shape)(nil)).Elem(), "type", []interface{}{CircleShape{}, SquareShape{}}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Drawing {
+    shapes: Shape[];
+    main: Shape;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.shapes = source["shapes"] ? source["shapes"].map((v1: any) => v1 ? createShape(v1) : v1) : source["shapes"];
+        this.main = source["main"] ? createShape(source["main"]) : source["main"];
+    }
+}
+export class CircleShape {
+    radius: number;
+    type: "CircleShape" = "CircleShape";
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.radius = source["radius"];
+    }
+}
+export class SquareShape {
+    side: number;
+    type: "SquareShape" = "SquareShape";
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.side = source["side"];
+    }
+}
+export type Shape = CircleShape | SquareShape;
+export function createShape(source: any = {}): Shape {
+    if ('string' === typeof source) source = JSON.parse(source);
+    switch (source["type"]) {
+        case "CircleShape":
+            return new CircleShape(source);
+        case "SquareShape":
+            return new SquareShape(source);
+    }
+    throw new Error("unknown type: " + source["type"]);
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Drawing({shapes: [{type: "CircleShape", radius: 1}, {type: "SquareShape", side: 2}]}).shapes[0] instanceof CircleShape`,
+		`new Drawing({shapes: [{type: "CircleShape", radius: 1}, {type: "SquareShape", side: 2}]}).shapes[1] instanceof SquareShape`,
+		`new Drawing({main: {type: "SquareShape", side: 2}}).main instanceof SquareShape`,
+	})
+}
+
+func TestReadonlyTag(t *testing.T) {
+	t.Parallel()
+	type DTO struct {
+		ID      int            `json:"id" ts_readonly:"true"`
+		Tags    []string       `json:"tags" ts_readonly:"true"`
+		Dummy   *Dummy         `json:"dummy" ts_readonly:"true"`
+		Meta    map[string]int `json:"meta,omitempty" ts_readonly:"true"`
+		Comment string         `json:"comment"`
+	}
+
+	converter := New().
+		Add(DTO{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class DTO {
+    readonly id: number;
+    readonly tags: string[];
+    readonly dummy?: Dummy | null;
+    readonly meta?: {[key: string]: number};
+    comment: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.tags = source["tags"];
+        this.dummy = this.convertValues(source["dummy"], Dummy);
+        this.meta = source["meta"];
+        this.comment = source["comment"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new DTO({id: 3}).id === 3`,
+	})
+}
+
+func TestSeeComments(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy   Dummy            `json:"dummy"`
+		Dummies []Dummy          `json:"dummies"`
+		ByKey   map[string]Dummy `json:"by_key"`
+		Name    string           `json:"name"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithInterface(true).
+		WithSeeComments(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Dummy {
+    something: string;
+}
+export interface Parent {
+    /** @see Dummy */
+    dummy: Dummy;
+    /** @see Dummy */
+    dummies: Dummy[];
+    /** @see Dummy */
+    by_key: {[key: string]: Dummy};
+    name: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestTopLevelTypeAliases(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(UserID("")).
+		Add(Tags{}).
+		Add(DummiesByKey{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export type UserID = string;
+export type Tags = string[];
+export interface Dummy {
+    something: string;
+}
+export type DummiesByKey = Record<string, Dummy>;`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestMemoizeCreateFrom(t *testing.T) {
+	t.Parallel()
+	type Graph struct {
+		Left  *Dummy `json:"left"`
+		Right *Dummy `json:"right"`
+	}
+
+	converter := New().
+		Add(Graph{}).
+		WithMemoizeCreateFrom(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        if (!source || 'object' !== typeof source) return new Dummy(source);
+        let cached = DummyCreateFromCache.get(source);
+        if (!cached) {
+            cached = new Dummy(source);
+            DummyCreateFromCache.set(source, cached);
+        }
+        return cached;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+const DummyCreateFromCache = new WeakMap<object, Dummy>();
+export class Graph {
+    left?: Dummy | null;
+    right?: Dummy | null;
+
+    static createFrom(source: any = {}) {
+        if (!source || 'object' !== typeof source) return new Graph(source);
+        let cached = GraphCreateFromCache.get(source);
+        if (!cached) {
+            cached = new Graph(source);
+            GraphCreateFromCache.set(source, cached);
+        }
+        return cached;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.left = this.convertValues(source["left"], Dummy);
+        this.right = this.convertValues(source["right"], Dummy);
+    }
+
+	` + strings.ReplaceAll(tsConvertValuesFunc, "return new classs(a);", "return classs.createFrom(a);") + `
+}
+const GraphCreateFromCache = new WeakMap<object, Graph>();`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(() => { const shared = {"something": "x"}; const g = Graph.createFrom({"left": shared, "right": shared}); return g.left === g.right; })()`,
+		`(() => { const source = {"left": {"something": "x"}}; return Graph.createFrom(source) === Graph.createFrom(source); })()`,
+		`Graph.createFrom({"left": {"something": "x"}}) !== Graph.createFrom({"left": {"something": "x"}})`,
+	})
+
+	// Only class instances created with new are memoized:
+	for _, converter := range []*TypeScriptify{
+		New().Add(Graph{}).WithMemoizeCreateFrom(true).WithLiteralCreateFrom(true),
+		New().Add(Graph{}).WithMemoizeCreateFrom(true).WithExternalCreateFrom(true),
+		New().Add(Graph{}).WithMemoizeCreateFrom(true).WithFreezeCreateFrom(true),
+	} {
+		_, err := converter.WithBackupDir("").Convert(nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, "a memoized createFrom can't be used with a literal, external or frozen createFrom", err.Error())
+	}
+}
+
+func TestTypeDiscriminator(t *testing.T) {
+	t.Parallel()
+	type Circle struct {
+		Radius float64 `json:"radius"`
+	}
+	type Square struct {
+		Side float64 `json:"side"`
+	}
+
+	converter := New().
+		Add(Circle{}).
+		Add(Square{}).
+		WithTypeDiscriminator("_type").
+		WithBackupDir("")
+
+	desiredResult := `export class Circle {
+    radius: number;
+    readonly _type: "Circle" = "Circle";
+
+    static createFrom(source: any = {}) {
+        return new Circle(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.radius = source["radius"];
+    }
+}
+export class Square {
+    side: number;
+    readonly _type: "Square" = "Square";
+
+    static createFrom(source: any = {}) {
+        return new Square(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.side = source["side"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Circle.createFrom({"radius": 1})._type === "Circle"`,
+		`[Circle.createFrom({"radius": 2}), Square.createFrom({"side": 3})].map(shape => shape._type === "Circle" ? shape.radius : shape.side).join() === "2,3"`,
+	})
+
+	literal, err := New().
+		Add(Circle{}).
+		WithLiteralCreateFrom(true).
+		WithTypeDiscriminator("_type").
+		WithBackupDir("").
+		Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, literal, `            _type: "Circle",
+        } as Circle;`)
+
+	// The TypeScript names:
+	type Drawing struct {
+		Main struct {
+			Name string `json:"name"`
+		} `json:"main"`
+	}
+	prefixed, err := New().
+		Add(Circle{}).
+		Add(Drawing{}).
+		WithPrefix("API").
+		WithAnonymousStructNames(true).
+		WithInterface(true).
+		WithTypeDiscriminator("_type").
+		WithBackupDir("").
+		Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, prefixed, `readonly _type: "APICircle";`)
+	assert.Contains(t, prefixed, `readonly _type: "APIDrawingMain";`)
+
+	// Subclasses can't redeclare the field:
+	type Ellipse struct {
+		Circle
+		Ratio float64 `json:"ratio"`
+	}
+	_, err = New().
+		Add(Ellipse{}).
+		WithInheritance(true).
+		WithTypeDiscriminator("_type").
+		WithBackupDir("").
+		Convert(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Ellipse extends Circle, the type discriminator _type can't be used with inheritance", err.Error())
+}
+
+func TestManageContainerType(t *testing.T) {
+	t.Parallel()
+	type Registry struct { // Marshalled (with a custom MarshalJSON) as an object
+		items sync.Map
+	}
+	type Queue struct { // Marshalled as an array
+		items []interface{}
+	}
+	type Inventory struct {
+		Items   Registry         `json:"items"`
+		Backup  *Registry        `json:"backup"`
+		Pending Queue            `json:"pending"`
+		Archive []Registry       `json:"archive"`
+		Queues  map[string]Queue `json:"queues"`
+	}
+
+	converter := New().
+		Add(Inventory{}).
+		ManageContainerType(reflect.TypeOf(Registry{}), map[string]Dummy{}).
+		ManageContainerType(Queue{}, []Dummy{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Inventory {
+    items: {[key: string]: Dummy};
+    backup?: {[key: string]: Dummy};
+    pending: Dummy[];
+    archive: {[key: string]: Dummy}[];
+    queues: {[key: string]: Dummy[]};
+
+    static createFrom(source: any = {}) {
+        return new Inventory(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.items = this.convertValues(source["items"], Dummy, true);
+        this.backup = this.convertValues(source["backup"], Dummy, true);
+        this.pending = this.convertValues(source["pending"], Dummy);
+        this.archive = this.convertValues(source["archive"], Dummy, true);
+        this.queues = this.convertValues(source["queues"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Inventory.createFrom({"items": {"a": {"something": "x"}}}).items["a"] instanceof Dummy`,
+		`Inventory.createFrom({"items": {}, "pending": [{"something": "y"}]}).pending[0] instanceof Dummy`,
+		`Inventory.createFrom({"archive": [{"a": {"something": "z"}}]}).archive[0]["a"] instanceof Dummy`,
+		`Inventory.createFrom({"queues": {"q": [{"something": "w"}]}}).queues["q"][0] instanceof Dummy`,
+	})
+}
+
+func TestImmutableSource(t *testing.T) {
+	t.Parallel()
+	type Catalog struct {
+		Items map[string]Dummy `json:"items"`
+		Main  Dummy            `json:"main"`
+	}
+
+	converter := New().
+		Add(Catalog{}).
+		WithImmutableSource(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: Readonly<any> = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: Readonly<any> = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Catalog {
+    items: {[key: string]: Dummy};
+    main: Dummy;
+
+    static createFrom(source: Readonly<any> = {}) {
+        return new Catalog(source);
+    }
+
+    constructor(source: Readonly<any> = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.items = this.convertValues(source["items"], Dummy, true);
+        this.main = this.convertValues(source["main"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(() => { const source = {"items": {"a": {"something": "x"}}}; const catalog = Catalog.createFrom(source); return catalog.items["a"] instanceof Dummy && !(source.items.a instanceof Dummy); })()`,
+		`Catalog.createFrom(Object.freeze({"items": Object.freeze({"a": Object.freeze({"something": "x"})})})).items["a"].something === "x"`,
+	})
+}
+
+func TestToFormData(t *testing.T) {
+	t.Parallel()
+	type Upload struct {
+		Title  string      `json:"title"`
+		Size   int         `json:"size"`
+		Public bool        `json:"public"`
+		Tags   []string    `json:"tags"`
+		Owner  *Dummy      `json:"owner,omitempty"`
+		File   interface{} `json:"file" ts_type:"Blob"`
+	}
+
+	converter := New().
+		Add(Upload{}).
+		WithToFormData(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+
+    toFormData(): FormData {
+        const formData = new FormData();
+        const append = (key: string, value: any) => {
+            if (value === undefined || value === null) return;
+            formData.append(key, value instanceof Blob ? value : 'object' === typeof value ? JSON.stringify(value) : String(value));
+        };
+        append("something", this.something);
+        return formData;
+    }
+}
+export class Upload {
+    title: string;
+    size: number;
+    public: boolean;
+    tags: string[];
+    owner?: Dummy | null;
+    file: Blob;
+
+    static createFrom(source: any = {}) {
+        return new Upload(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.title = source["title"];
+        this.size = source["size"];
+        this.public = source["public"];
+        this.tags = source["tags"];
+        this.owner = this.convertValues(source["owner"], Dummy);
+        this.file = source["file"];
+    }
+
+    toFormData(): FormData {
+        const formData = new FormData();
+        const append = (key: string, value: any) => {
+            if (value === undefined || value === null) return;
+            formData.append(key, value instanceof Blob ? value : 'object' === typeof value ? JSON.stringify(value) : String(value));
+        };
+        append("title", this.title);
+        append("size", this.size);
+        append("public", this.public);
+        append("tags", this.tags);
+        append("owner", this.owner);
+        append("file", this.file);
+        return formData;
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	upload := `Upload.createFrom({"title": "t", "size": 3, "public": true, "tags": ["a", "b"], "owner": {"something": "x"}, "file": new Blob(["content"])}).toFormData()`
+	testConverter(t, converter, true, desiredResult, []string{
+		upload + `.get("title") === "t"`,
+		upload + `.get("size") === "3"`,
+		upload + `.get("public") === "true"`,
+		upload + `.get("tags") === '["a","b"]'`,
+		upload + `.get("owner") === '{"something":"x"}'`,
+		upload + `.get("file") instanceof Blob`,
+		`!Upload.createFrom({"title": "t"}).toFormData().has("owner")`,
+	})
+}
+
+func TestAnonymousStructNames(t *testing.T) {
+	t.Parallel()
+	type Order struct {
+		ID   string `json:"id"`
+		Meta struct {
+			Source string `json:"source"`
+			Audit  struct {
+				User string `json:"user"`
+			} `json:"audit"`
+		} `json:"meta"`
+	}
+
+	converter := New().
+		Add(Order{}).
+		WithAnonymousStructNames(true).
+		WithStrictNames(true).
+		WithBackupDir("")
+
+	desiredResult := `export class OrderMetaAudit {
+    user: string;
+
+    static createFrom(source: any = {}) {
+        return new OrderMetaAudit(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.user = source["user"];
+    }
+}
+export class OrderMeta {
+    source: string;
+    audit: OrderMetaAudit;
+
+    static createFrom(source: any = {}) {
+        return new OrderMeta(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.source = source["source"];
+        this.audit = this.convertValues(source["audit"], OrderMetaAudit);
+    }
+
+	` + tsConvertValuesFunc + `
+}
+export class Order {
+    id: string;
+    meta: OrderMeta;
+
+    static createFrom(source: any = {}) {
+        return new Order(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+        this.meta = this.convertValues(source["meta"], OrderMeta);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Order.createFrom({"meta": {"audit": {"user": "me"}}}).meta.audit instanceof OrderMetaAudit`,
+	})
+}
+
+func TestNullishOptionals(t *testing.T) {
+	t.Parallel()
+	type Order struct {
+		Customer *Dummy `json:"customer,omitempty"`
+		Seller   *Dummy `json:"seller"`
+		Note     string `json:"note,omitempty"`
+	}
+
+	converter := New().
+		Add(Order{}).
+		WithNullishOptionals(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Order {
+    customer?: Dummy | null | undefined;
+    seller?: Dummy | null;
+    note?: string;
+
+    static createFrom(source: any = {}) {
+        return new Order(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.customer = this.convertValues(source["customer"], Dummy);
+        this.seller = this.convertValues(source["seller"], Dummy);
+        this.note = source["note"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Order.createFrom({"customer": null}).customer === null`,
+		`Order.createFrom({}).customer === undefined`,
+		`Order.createFrom({"customer": {"something": "x"}}).customer instanceof Dummy`,
+	})
+}
+
+func TestNullablePointers(t *testing.T) {
+	t.Parallel()
+	type Pointers struct {
+		Int     *int              `json:"int"`
+		String  *string           `json:"string"`
+		Bool    *bool             `json:"bool"`
+		Weekday *Weekday          `json:"weekday"`
+		Time    *time.Time        `json:"time"`
+		Struct  *Dummy            `json:"struct"`
+		Slice   *[]string         `json:"slice"`
+		Array   *[2]int           `json:"array"`
+		Map     *map[string]int   `json:"map"`
+		Structs *map[string]Dummy `json:"structs"`
+		Custom  *int              `json:"custom" ts_type:"string"`
+	}
+
+	converter := New().
+		AddEnum(allWeekdaysV2).
+		Add(Pointers{}).
+		WithNullablePointers(true).
+		WithTimeType("Date").
+		WithBackupDir("")
+
+	desiredResult := `export enum Weekday {
+	SUNDAY = 0,
+	MONDAY = 1,
+	TUESDAY = 2,
+	WEDNESDAY = 3,
+	THURSDAY = 4,
+	FRIDAY = 5,
+	SATURDAY = 6,
+}
+export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+        return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Pointers {
+    int?: number | null;
+    string?: string | null;
+    bool?: boolean | null;
+    weekday?: Weekday | null;
+    time?: Date | null;
+    struct?: Dummy | null;
+    slice?: string[] | null;
+    array?: number[] | null;
+    map?: {[key: string]: number} | null;
+    structs?: {[key: string]: Dummy} | null;
+    custom?: string;
+
+    static createFrom(source: any = {}) {
+        return new Pointers(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.int = source["int"];
+        this.string = source["string"];
+        this.bool = source["bool"];
+        this.weekday = source["weekday"];
+        this.time = source["time"] ? new Date(source["time"]) : source["time"];
+        this.struct = this.convertValues(source["struct"], Dummy);
+        this.slice = source["slice"];
+        this.array = source["array"];
+        this.map = source["map"];
+        this.structs = this.convertValues(source["structs"], Dummy, true);
+        this.custom = source["custom"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	var assertions []string
+	for _, field := range []string{"int", "string", "bool", "weekday", "time", "struct", "slice", "array", "map", "structs"} {
+		assertions = append(assertions,
+			fmt.Sprintf(`Pointers.createFrom({%q: null}).%s === null`, field, field),
+			fmt.Sprintf(`Pointers.createFrom({}).%s === undefined`, field))
+	}
+	assertions = append(assertions,
+		`Pointers.createFrom({"time": "2020-01-02T00:00:00Z"}).time?.getUTCDate() === 2`,
+		`Pointers.createFrom({"structs": {"a": {"something": "x"}}}).structs?.["a"] instanceof Dummy`)
+	testConverter(t, converter, true, desiredResult, assertions)
+}
+
+func TestPointerToNamedSlice(t *testing.T) {
+	t.Parallel()
+	type Post struct {
+		Tags  *Tags `json:"tags"`
+		Plain Tags  `json:"plain"`
+	}
+
+	converter := New().
+		Add(Post{}).
+		WithBackupDir("")
+
+	desiredResult := `export class Post {
+    tags?: string[] | null;
+    plain: string[];
+
+    static createFrom(source: any = {}) {
+        return new Post(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.tags = source["tags"];
+        this.plain = source["plain"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Post.createFrom({"tags": ["a", "b"], "plain": []}).tags?.length === 2`,
+		`Post.createFrom({"tags": null, "plain": []}).tags === null`,
+	})
+}
+
+func TestBrandedScalars(t *testing.T) {
+	t.Parallel()
+	type UserID int
+	type User struct {
+		ID     UserID  `json:"id"`
+		Parent *UserID `json:"parent"`
+	}
+
+	converter := New().
+		Add(User{}).
+		WithBrandedScalars(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export type UserID = number & { readonly __brand: 'UserID' };
+export interface User {
+    id: UserID;
+    parent?: UserID;
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`({id: 1 as UserID} as User).id === 1`,
+	})
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Name  string `json:"name"`
+		Dummy Dummy  `json:"dummy"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithClone(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+
+    clone(): Dummy {
+        return new Dummy(JSON.parse(JSON.stringify(this)));
+    }
+}
+export class Parent {
+    name: string;
+    dummy: Dummy;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.dummy = this.convertValues(source["dummy"], Dummy);
+    }
+
+    clone(): Parent {
+        return new Parent(JSON.parse(JSON.stringify(this)));
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(() => { const a = new Parent({name: "a", dummy: {something: "a"}}); const b = a.clone(); b.dummy.something = "b"; return a.dummy.something === "a" && b.dummy instanceof Dummy; })()`,
+	})
+
+	// The declarations have clone() only if the class has it:
+	for _, constructor := range []bool{true, false} {
+		class, err := New().Add(Parent{}).WithClone(true).WithConstructor(constructor).WithCreateFromMethod(false).WithBackupDir("").Convert(nil)
+		assert.Nil(t, err)
+		declarations, err := New().Add(Parent{}).WithClone(true).WithConstructor(constructor).WithCreateFromMethod(false).WithDeclarationOnly(true).WithBackupDir("").Convert(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.Contains(class, "clone(): Parent {"), strings.Contains(declarations, "clone(): Parent;"))
+		assert.Equal(t, constructor, strings.Contains(declarations, "clone(): Parent;"))
+	}
+}
+
+func TestSetter(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Address{}).
+		WithSetter(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Address {
+    duration: number;
+    text?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.duration = source["duration"];
+        this.text = source["text"];
+    }
+
+    set<K extends keyof Address>(key: K, value: Address[K]): void {
+        (this as Address)[key] = value;
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(() => { const a = new Address({duration: 1}); a.set("duration", 2); return a.duration === 2; })()`,
+	})
+}
+
+func TestNullValue(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy   Dummy            `json:"dummy"`
+		Dummies []Dummy          `json:"dummies"`
+		ByKey   map[string]Dummy `json:"by_key"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithNullValue(NullValueNull).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    dummy: Dummy | null;
+    dummies: Dummy[] | null;
+    by_key: {[key: string]: Dummy} | null;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.dummy = source["dummy"] ? this.convertValues(source["dummy"], Dummy) : null;
+        this.dummies = source["dummies"] ? this.convertValues(source["dummies"], Dummy) : null;
+        this.by_key = source["by_key"] ? this.convertValues(source["by_key"], Dummy, true) : null;
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Parent({}).dummy === null`,
+	})
+
+	converter = New().
+		Add(Parent{}).
+		WithNullValue(NullValueSkip).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult = `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Parent {
+    dummy?: Dummy;
+    dummies?: Dummy[];
+    by_key?: {[key: string]: Dummy};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        if (source["dummy"]) this.dummy = this.convertValues(source["dummy"], Dummy);
+        if (source["dummies"]) this.dummies = this.convertValues(source["dummies"], Dummy);
+        if (source["by_key"]) this.by_key = this.convertValues(source["by_key"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Parent({}).dummy === undefined`,
+		`new Parent({dummy: {something: "x"}}).dummy instanceof Dummy`,
+	})
+}
+
+func TestNumberCoercion(t *testing.T) {
+	t.Parallel()
+	type Measurements struct {
+		Count   int            `json:"count"`
+		Ratio   *float64       `json:"ratio"`
+		Values  []float64      `json:"values"`
+		ByName  map[string]int `json:"by_name"`
+		Comment string         `json:"comment"`
+	}
+
+	converter := New().
+		Add(Measurements{}).
+		WithNumberCoercion("Number").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Measurements {
+    count: number;
+    ratio?: number;
+    values: number[];
+    by_name: {[key: string]: number};
+    comment: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.count = Number(source["count"]);
+        this.ratio = source["ratio"] ? Number(source["ratio"]) : source["ratio"];
+        this.values = source["values"] ? source["values"].map((v1: any) => Number(v1)) : source["values"];
+        this.by_name = source["by_name"] ? Object.keys(source["by_name"]).reduce((m: any, k: string) => { m[k] = Number(source["by_name"][k]); return m; }, {}) : source["by_name"];
+        this.comment = source["comment"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Measurements({count: "3"}).count === 3`,
+		`new Measurements({values: ["1.5"]}).values[0] === 1.5`,
+		`new Measurements({by_name: {a: "2"}}).by_name["a"] === 2`,
+	})
+}
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy Dummy `json:"dummy"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithNamespace("Models").
+		WithBackupDir("")
+
+	desiredResult := `export namespace Models {
+    export class Dummy {
+        something: string;
+
+        static createFrom(source: any = {}) {
+            return new Dummy(source);
+        }
+
+        constructor(source: any = {}) {
+            if ('string' === typeof source) source = JSON.parse(source);
+            this.something = source["something"];
+        }
+    }
+    export class Parent {
+        dummy: Dummy;
+
+        static createFrom(source: any = {}) {
+            return new Parent(source);
+        }
+
+        constructor(source: any = {}) {
+            if ('string' === typeof source) source = JSON.parse(source);
+            this.dummy = this.convertValues(source["dummy"], Dummy);
+        }
+
+	` + tsConvertValuesFunc + `
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Models.Parent.createFrom({dummy: {something: "x"}}).dummy instanceof Models.Dummy`,
+	})
+}
+
+func TestDefaultExportNamespace(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy Dummy `json:"dummy"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithNamespace("Models").
+		WithDefaultExportNamespace(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export namespace Models {
+    export interface Dummy {
+        something: string;
+    }
+    export interface Parent {
+        dummy: Dummy;
+    }
+}
+export default Models;`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(({dummy: {something: "x"}}) as Models.Parent).dummy.something === "x"`,
+	})
+}
+
+func TestNamespaceIndentation(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy Dummy `json:"dummy"`
+	}
+
+	converter := New().
+		AddEnum(allGenders).
+		Add(Parent{}).
+		WithNamespace("Models").
+		WithIndent("  ").
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(map[string]string{"Dummy": "  hello() {\n    return 1;\n  }"})
+	assert.Nil(t, err)
+	// The members are indented two levels (the namespace and the class), the custom code stays as it is:
+	assert.Equal(t, `
+export namespace Models {
+  export enum Gender {
+    MALE = "m",
+    FEMALE = "f",
+  }
+  export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}) {
+      return new Dummy(source);
+    }
+
+    constructor(source: any = {}) {
+      if ('string' === typeof source) source = JSON.parse(source);
+      this.something = source["something"];
+    }
+    //[Dummy:]
+  hello() {
+    return 1;
+  }
+
+    //[end]
+  }
+  export class Parent {
+    dummy: Dummy;
+
+    static createFrom(source: any = {}) {
+      return new Parent(source);
+    }
+
+    constructor(source: any = {}) {
+      if ('string' === typeof source) source = JSON.parse(source);
+      this.dummy = this.convertValues(source["dummy"], Dummy);
+    }
+
+    convertValues(a: any, classs: any, asMap: boolean = false): any {
+      if (!a) {
+        return a;
+      }`, typeScriptCode[:strings.Index(typeScriptCode, "      if (a.slice")-1])
+	assert.True(t, strings.HasSuffix(typeScriptCode, "\n    }\n  }\n}"))
+}
+
+func TestAddMany(t *testing.T) {
+	t.Parallel()
+	f, err := ioutil.TempFile(os.TempDir(), "*.ts")
+	assert.Nil(t, err)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	converter := New()
+	assert.True(t, converter == converter.AddMany(Dummy{}, Address{}))
+	assert.True(t, converter == converter.Add(Book{}))
+	assert.True(t, converter == converter.AddType(reflect.TypeOf(Dummy{})))
+
+	err = New().
+		AddMany(Dummy{}, Address{}).
+		Add(Book{}).
+		WithInterface(true).
+		WithHeader("").
+		WithBackupDir("").
+		ConvertToFile(f.Name())
+	assert.Nil(t, err)
+	code, err := ioutil.ReadFile(f.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, `export interface Dummy {
+    something: string;
+}
+export interface Address {
+    duration: number;
+    text?: string;
+}
+export interface Author {
+    name: string;
+    books: Book[];
+}
+export interface Book {
+    title: string;
+    author?: Author | null;
+}
+`, string(code))
+}
+
+func TestConvertToGoConst(t *testing.T) {
+	t.Parallel()
+	type Greeting struct {
+		Text string "json:\"text\" ts_transform:\"`Hello ${__VALUE__}`\""
+	}
+
+	for _, converter := range []*TypeScriptify{
+		New().Add(Dummy{}).WithBackupDir(""),
+		New().Add(Greeting{}).WithBackupDir(""), // With a backtick
+		New().Add(Dummy{}).WithLineEnding("\r\n").WithBackupDir(""),
+	} {
+		var expected bytes.Buffer
+		assert.Nil(t, converter.ConvertToWriter(&expected, nil))
+
+		src, err := converter.ConvertToGoConst("models", "ModelsTS")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(src, "// Code generated by typescriptify. DO NOT EDIT.\n\npackage models\n"))
+
+		file, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0)
+		assert.Nil(t, err)
+		spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+		assert.Equal(t, "ModelsTS", spec.Names[0].Name)
+		code, err := strconv.Unquote(spec.Values[0].(*ast.BasicLit).Value)
+		assert.Nil(t, err)
+		assert.Equal(t, expected.String(), code)
+	}
+}
+
+func TestNamespaceKeepsCustomCode(t *testing.T) {
+	t.Parallel()
+	f, err := ioutil.TempFile(os.TempDir(), "*.ts")
+	assert.Nil(t, err)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	customCode := "        //[Dummy:]\n        hello() {\n            return 1;\n        }\n\n        //[end]\n"
+	assert.Nil(t, ioutil.WriteFile(f.Name(), []byte(customCode), 0644))
+
+	converter := New().
+		Add(Dummy{}).
+		WithNamespace("Models").
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	assert.Nil(t, converter.ConvertToFile(f.Name()))
+	first, err := ioutil.ReadFile(f.Name())
+	assert.Nil(t, err)
+	assert.Contains(t, string(first), `
+export namespace Models {
+    export class Dummy {
+        something: string;
+        //[Dummy:]
+        hello() {
+            return 1;
+        }
+
+        //[end]
+    }
+}`)
+
+	assert.Nil(t, converter.ConvertToFile(f.Name()))
+	second, err := ioutil.ReadFile(f.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, string(first), string(second))
+}
+
+func TestBackups(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir(os.TempDir(), "ts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	backupDir := path.Join(dir, "backup")
+	assert.Nil(t, os.Mkdir(backupDir, 0755))
+
+	fileName := path.Join(dir, "models.ts")
+	countBackups := func() int {
+		files, err := ioutil.ReadDir(backupDir)
+		assert.Nil(t, err)
+		return len(files)
+	}
+
+	// No backups by default:
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, New().Add(Dummy{}).ConvertToFile(fileName))
+	}
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files)) // models.ts and the backup dir
+	assert.Equal(t, 0, countBackups())
+
+	// The fixed backup is overwritten:
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, New().Add(Dummy{}).WithBackupDir(backupDir).WithFixedBackupName(true).ConvertToFile(fileName))
+	}
+	assert.Equal(t, 1, countBackups())
+	backup, err := ioutil.ReadFile(path.Join(backupDir, "models.ts.backup"))
+	assert.Nil(t, err)
+	current, err := ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	assert.Equal(t, string(current), string(backup))
+}
+
+func TestConvertIntoFile(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir(os.TempDir(), "ts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	fileName := path.Join(dir, "module.ts")
+
+	converter := New().
+		Add(Dummy{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithHeader("").
+		WithBackupDir("")
+
+	// Without markers, they are appended:
+	assert.Nil(t, ioutil.WriteFile(fileName, []byte("import x from 'x';\n\n"), 0644))
+	assert.Nil(t, converter.ConvertIntoFile(fileName))
+	content, err := ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	assert.Equal(t, "import x from 'x';\n\n// <generated>\nexport class Dummy {\n    something: string;\n}\n// </generated>\n", string(content))
+
+	// Only the code between the markers is replaced:
+	handwritten := "import x from 'x';\n\n// <generated>\nold code\n// </generated>\n\nexport function hello() {\n    return x;\n}\n"
+	assert.Nil(t, ioutil.WriteFile(fileName, []byte(handwritten), 0644))
+	assert.Nil(t, converter.ConvertIntoFile(fileName))
+	content, err = ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	expected := "import x from 'x';\n\n// <generated>\nexport class Dummy {\n    something: string;\n}\n// </generated>\n\nexport function hello() {\n    return x;\n}\n"
+	assert.Equal(t, expected, string(content))
+
+	// Regenerating doesn't change anything:
+	assert.Nil(t, converter.ConvertIntoFile(fileName))
+	content, err = ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(content))
+
+	// A new file:
+	newFileName := path.Join(dir, "new.ts")
+	assert.Nil(t, converter.ConvertIntoFile(newFileName))
+	content, err = ioutil.ReadFile(newFileName)
+	assert.Nil(t, err)
+	assert.Equal(t, "// <generated>\nexport class Dummy {\n    something: string;\n}\n// </generated>\n", string(content))
+
+	// A start marker without the end marker:
+	unterminated := "import x from 'x';\n\n// <generated>\nold code\n"
+	assert.Nil(t, ioutil.WriteFile(fileName, []byte(unterminated), 0644))
+	err = converter.ConvertIntoFile(fileName)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "without a // </generated> marker")
+	content, err = ioutil.ReadFile(fileName)
+	assert.Nil(t, err)
+	assert.Equal(t, unterminated, string(content))
+}
+
+func TestConvertToDir(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir(os.TempDir(), "ts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	customCode := "    //[Author:]\n    hello() {\n        return 1;\n    }\n\n    //[end]\n"
+	assert.Nil(t, ioutil.WriteFile(path.Join(dir, "Author.ts"), []byte(customCode), 0644))
+
+	converter := New().
+		Add(Book{}).
+		Add(Dummy{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithHeader("").
+		WithBackupDir("")
+	assert.Nil(t, converter.ConvertToDir(dir))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(files))
+
+	book, err := ioutil.ReadFile(path.Join(dir, "Book.ts"))
+	assert.Nil(t, err)
+	assert.Equal(t, "import { Author } from './Author';\n\nexport class Book {\n    title: string;\n    author?: Author | null;\n}\n", string(book))
+
+	// Circular imports are fine:
+	author, err := ioutil.ReadFile(path.Join(dir, "Author.ts"))
+	assert.Nil(t, err)
+	assert.Equal(t, "import { Book } from './Book';\n\nexport class Author {\n    name: string;\n    books: Book[];\n    //[Author:]\n    hello() {\n        return 1;\n    }\n\n    //[end]\n}\n", string(author))
+
+	dummy, err := ioutil.ReadFile(path.Join(dir, "Dummy.ts"))
+	assert.Nil(t, err)
+	assert.Equal(t, "export class Dummy {\n    something: string;\n}\n", string(dummy))
+}
+
+func TestConvertToDirImportedFunctions(t *testing.T) {
+	t.Parallel()
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Child struct {
+		Base
+		Dummy Dummy `json:"dummy"`
+	}
+
+	dir, err := ioutil.TempDir(os.TempDir(), "ts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	converter := New().
+		Add(Child{}).
+		WithInheritance(true).
+		WithExternalCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithFieldNames(true).
+		WithHeader("").
+		WithBackupDir("")
+	assert.Nil(t, converter.ConvertToDir(dir))
+
+	// The createXxx() functions and XxxFields are imported with the types, convertValues() is in the file:
+	child, err := ioutil.ReadFile(path.Join(dir, "Child.ts"))
+	assert.Nil(t, err)
+	assert.Equal(t, `import { Base, createBase, BaseFields } from './Base';
+import { Dummy, createDummy } from './Dummy';
+
+`+strings.ReplaceAll(tsSplitConvertValuesFunc, "\t", "    ")+`
+
+export class Child extends Base {
+    dummy!: Dummy;
+
+    static createFrom(source: any = {}): Child {
+        return createChild(source);
+    }
+}
+export function createChild(source: any = {}): Child {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return Object.assign(Object.create(Child.prototype), {
+        ...createBase(source),
+        dummy: convertValues(source["dummy"], createDummy),
+    }) as Child;
+}
+export const ChildFields = [...BaseFields, "dummy"] as const;
+`, string(child))
+
+	dummy, err := ioutil.ReadFile(path.Join(dir, "Dummy.ts"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(dummy), "import ")
+	assert.NotContains(t, string(dummy), "function convertValues(")
+}
+
+func TestFuncFieldsWithTSType(t *testing.T) {
+	t.Parallel()
+	type Widget struct {
+		Name     string         `json:"name"`
+		OnChange func(x int)    `json:"on_change" ts_type:"(x: number) => void"`
+		Ignored  func() float64 `json:"ignored"`
+	}
+
+	converter := New().
+		Add(Widget{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Widget {
+    name: string;
+    on_change?: (x: number) => void;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Widget({name: "w"}).on_change === undefined`,
+	})
+}
+
+type DocumentedUser struct {
+	Name  string `json:"name" ts_doc:"The full name"`
+	Email string `json:"email" ts_doc:"The email address.\nMust be verified."`
+}
+
+func (DocumentedUser) TSDoc() string {
+	return "A registered user"
+}
+
+func TestDocComments(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(DocumentedUser{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `/** A registered user */
+export interface DocumentedUser {
+    /** The full name */
+    name: string;
+    /**
+     * The email address.
+     * Must be verified.
+     */
+    email: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestSourceComments(t *testing.T) {
+	t.Parallel()
+	type Account struct {
+		User DocumentedUser `json:"user"`
+		Day  Weekday        `json:"day"`
+	}
+
+	converter := New().
+		Add(Account{}).
+		AddEnum(allWeekdaysV1).
+		WithSourceComments(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `/** @source github.com/tkrajina/typescriptify-golang-structs/typescriptify.Weekday */
+export enum Weekday {
+    SUNDAY = 0,
+    MONDAY = 1,
+    TUESDAY = 2,
+    WEDNESDAY = 3,
+    THURSDAY = 4,
+    FRIDAY = 5,
+    SATURDAY = 6,
+}
+/**
+ * A registered user
+ * @source github.com/tkrajina/typescriptify-golang-structs/typescriptify.DocumentedUser
+ */
+export interface DocumentedUser {
+    /** The full name */
+    name: string;
+    /**
+     * The email address.
+     * Must be verified.
+     */
+    email: string;
+}
+/** @source github.com/tkrajina/typescriptify-golang-structs/typescriptify.Account */
+export interface Account {
+    user: DocumentedUser;
+    day: Weekday;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+type Decimal struct {
+	Value string
+}
+
+func (Decimal) TypeScriptType() (string, string) {
+	return "string", ""
+}
+
+type Cents int64
+
+func (Cents) TypeScriptType() (string, string) {
+	return "bigint", "BigInt(__VALUE__)"
+}
+
+func TestTSTyper(t *testing.T) {
+	t.Parallel()
+	type Invoice struct {
+		Total    Decimal `json:"total"`
+		Discount Cents   `json:"discount"`
+		Tax      Decimal `json:"tax" ts_type:"number"`
+	}
+
+	converter := New().
+		Add(Invoice{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Invoice {
+    total: string;
+    discount: bigint;
+    tax: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.total = source["total"];
+        this.discount = BigInt(source["discount"]);
+        this.tax = source["tax"];
+    }
+}`
+	testConverter(t, converter, false, desiredResult, nil)
+}
+
+func TestInterfaceType(t *testing.T) {
+	t.Parallel()
+	type Loose struct {
+		Value  interface{}              `json:"value"`
+		Values []interface{}            `json:"values"`
+		Data   map[string]interface{}   `json:"data"`
+		Nested map[string][]interface{} `json:"nested"`
+	}
+
+	converter := New().
+		Add(Loose{}).
+		WithInterfaceType("unknown").
+		WithBackupDir("")
+
+	desiredResult := `export class Loose {
+    value: unknown;
+    values: unknown[];
+    data: {[key: string]: unknown};
+    nested: {[key: string]: unknown[]};
+
+    static createFrom(source: any = {}) {
+        return new Loose(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.value = source["value"];
+        this.values = source["values"];
+        this.data = source["data"];
+        this.nested = source["nested"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Loose.createFrom({"value": 1, "values": ["a", 2], "data": {"x": true}}).values.length === 2`,
+	})
+
+	// Converting doesn't change the type mappings, so the InterfaceType can be changed back:
+	converter.WithInterfaceType("")
+	code, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, code, "value: any;")
+
+	// An explicit type mapping has precedence over the InterfaceType:
+	converter.WithInterfaceType("unknown").AddTypeMapping(reflect.Interface, "object")
+	code, err = converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, code, "value: object;")
+}
+
+func TestRawMessage(t *testing.T) {
+	t.Parallel()
+	type Event struct {
+		Data    json.RawMessage            `json:"data"`
+		History []json.RawMessage          `json:"history"`
+		Extra   map[string]json.RawMessage `json:"extra"`
+		Bytes   []byte                     `json:"bytes"`
+	}
+
+	converter := New().
+		Add(Event{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Event {
+    data: any;
+    history: any[];
+    extra: {[key: string]: any};
+    bytes: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.data = source["data"];
+        this.history = source["history"];
+        this.extra = source["extra"];
+        this.bytes = source["bytes"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Event({data: {a: [1]}}).data.a[0] === 1`,
+	})
+
+	converter = New().
+		Add(Event{}).
+		WithRawMessageType("unknown").
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult = `export interface Event {
+    data: unknown;
+    history: unknown[];
+    extra: {[key: string]: unknown};
+    bytes: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestClassValidator(t *testing.T) {
+	t.Parallel()
+	type Order struct {
+		ID       string    `json:"id"`
+		Amount   float64   `json:"amount"`
+		Note     *string   `json:"note"`
+		Address  Address   `json:"address"`
+		Previous []Address `json:"previous"`
+	}
+
+	converter := New().
+		Add(Order{}).
+		WithClassValidator(true).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	typeScriptCode, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `import { IsArray, IsNumber, IsOptional, IsString, ValidateNested } from 'class-validator';
+
+export class Address {
+    @IsNumber()
+    duration: number;
+    @IsOptional()
+    @IsString()
+    text?: string;
+}
+export class Order {
+    @IsString()
+    id: string;
+    @IsNumber()
+    amount: number;
+    @IsOptional()
+    @IsString()
+    note?: string;
+    @ValidateNested()
+    address: Address;
+    @IsArray()
+    @ValidateNested({ each: true })
+    previous: Address[];
+}`, typeScriptCode)
+}
+
+func TestHashComment(t *testing.T) {
+	t.Parallel()
+	convert := func(obj interface{}) string {
+		var buf bytes.Buffer
+		converter := New().
+			Add(obj).
+			WithHashComment(true).
+			WithBackupDir("")
+		assert.Nil(t, converter.ConvertToWriter(&buf, nil))
+		return strings.Split(buf.String(), "\n")[2]
+	}
+
+	hash := convert(Dummy{})
+	assert.Regexp(t, `^/\* Hash: [0-9a-f]{64} \*/$`, hash)
+	assert.Equal(t, hash, convert(Dummy{}))
+	assert.NotEqual(t, hash, convert(Address{}))
+
+	// Every file has a hash comment:
+	dir, err := ioutil.TempDir(os.TempDir(), "ts")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	converter := New().
+		Add(Dummy{}).
+		WithHashComment(true).
+		WithHeader("").
+		WithBackupDir("")
+	assert.Nil(t, converter.ConvertToFiles(path.Join(dir, "types.ts"), path.Join(dir, "converters.ts")))
+	assert.Nil(t, converter.ConvertToDir(dir))
+	for _, fileName := range []string{"types.ts", "converters.ts", "Dummy.ts"} {
+		byts, err := ioutil.ReadFile(path.Join(dir, fileName))
+		assert.Nil(t, err)
+		assert.Regexp(t, `^/\* Hash: [0-9a-f]{64} \*/\n`, string(byts), fileName)
+	}
+}
+
+func TestPartialSourceType(t *testing.T) {
+	t.Parallel()
+	type User struct {
+		BaseEntity
+		Name string `json:"name"`
+	}
+
+	converter := New().
+		Add(User{}).
+		WithInheritance(true).
+		WithSourceType("Partial<__TYPE__>").
+		WithConstructor(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	desiredResult := `export class BaseEntity {
+    id: number;
+
+    static createFrom(source: Partial<BaseEntity> = {}) {
+        return new BaseEntity(source);
+    }
+
+    constructor(input: Partial<BaseEntity> = {}) {
+        let source: any = input;
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.id = source["id"];
+    }
+}
+export class User extends BaseEntity {
+    name: string;
+
+    static createFrom(source: Partial<User> = {}) {
+        return new User(source);
+    }
+
+    constructor(input: Partial<User> = {}) {
+        super(input);
+        let source: any = input;
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new User({id: 1, name: "Jane"}).id === 1`,
+		`User.createFrom({name: "Jane"}).name === "Jane"`,
+	})
+}
+
+func TestDuplicateEntityNames(t *testing.T) {
+	t.Parallel()
+	converter := New().
+		Add(Address{}).
+		Add(models.Address{}).
+		WithBackupDir("")
+
+	_, err := converter.Convert(nil)
+	assert.EqualError(t, err, "github.com/tkrajina/typescriptify-golang-structs/typescriptify.Address and github.com/tkrajina/typescriptify-golang-structs/example/models.Address are both converted to Address")
+}
+
+func TestDuplicateEntityNamesAfterStripSuffix(t *testing.T) {
+	t.Parallel()
+	type User struct {
+		Name string `json:"name"`
+	}
+	type UserDTO struct {
+		Name string `json:"name"`
+	}
+	converter := New().
+		Add(User{}).
+		Add(UserDTO{}).
+		WithStripSuffix("DTO").
+		WithBackupDir("")
+
+	_, err := converter.Convert(nil)
+	assert.EqualError(t, err, "github.com/tkrajina/typescriptify-golang-structs/typescriptify.User and github.com/tkrajina/typescriptify-golang-structs/typescriptify.UserDTO are both converted to User")
+}
+
+type Named interface {
+	GetName() string
+}
+
+func TestImplementInterfaces(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Named
+		Name string `json:"name"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		SetCustomCode("Named", "    getName(): string;").
+		SetCustomCode("Person", "    getName() { return this.name; }").
+		WithImplementInterfaces(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export interface Named {
+    //[Named:]
+    getName(): string;
+
+    //[end]
+}
+export class Person implements Named {
+    name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+    }
+    //[Person:]
+    getName() { return this.name; }
+
+    //[end]
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Person({name: "Jane"}).getName() === "Jane"`,
+	})
+
+	converter = New().
+		Add(Person{}).
+		WithImplementInterfaces(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult = `export interface Named {
+}
+export interface Person extends Named {
+    name: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestCustomIndentNesting(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Dummy Dummy `json:"dummy"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithIndent("  ").
+		WithLiteralCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	converted, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, converted, `export class Parent {
+  dummy: Dummy;
+
+  static createFrom(source: any = {}): Parent {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return {
+      dummy: this.createFromValues(source["dummy"], Dummy),
+    } as Parent;
+  }`)
+	assert.Contains(t, converted, `
+  static createFromValues(a: any, classs: any, asMap: boolean = false): any {
+    if (!a) {
+      return a;
+    }`)
+}
+
+func TestMapOfStructSlices(t *testing.T) {
+	t.Parallel()
+	type Catalog struct {
+		Groups map[string][]Dummy `json:"groups"`
+	}
+
+	converter := New().
+		Add(Catalog{}).
+		WithLiteralCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    static createFrom(source: any = {}): Dummy {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            something: source["something"],
+        } as Dummy;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Catalog {
+    groups: {[key: string]: Dummy[]};
+
+    static createFrom(source: any = {}): Catalog {
+        if ('string' === typeof source) source = JSON.parse(source);
+        return {
+            groups: this.createFromValues(source["groups"], Dummy, true),
+        } as Catalog;
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.groups = this.convertValues(source["groups"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+
+	` + tsCreateFromValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Catalog.createFrom({groups: {a: [{something: "x"}]}}).groups["a"][0].something === "x"`,
+		`Catalog.createFrom({groups: {a: [{something: "x"}]}}).groups["a"].length === 1`,
+	})
+}
+
+func TestExternalCreateFrom(t *testing.T) {
+	t.Parallel()
+	type Parent struct {
+		Name  string `json:"name"`
+		Dummy Dummy  `json:"dummy"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithExternalCreateFrom(true).
+		WithCreateFromMethod(true).
+		WithClone(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something!: string;
+
+    static createFrom(source: any = {}): Dummy {
+        return createDummy(source);
+    }
+
+    clone(): Dummy {
+        return createDummy(JSON.parse(JSON.stringify(this)));
+    }
+}
+export function createDummy(source: any = {}): Dummy {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return Object.assign(Object.create(Dummy.prototype), {
+        something: source["something"],
+    }) as Dummy;
+}
+export class Parent {
+    name!: string;
+    dummy!: Dummy;
+
+    static createFrom(source: any = {}): Parent {
+        return createParent(source);
+    }
+
+    clone(): Parent {
+        return createParent(JSON.parse(JSON.stringify(this)));
+    }
+}
+export function createParent(source: any = {}): Parent {
+    if ('string' === typeof source) source = JSON.parse(source);
+    return Object.assign(Object.create(Parent.prototype), {
+        name: source["name"],
+        dummy: convertValues(source["dummy"], createDummy),
+    }) as Parent;
+}
+` + tsSplitConvertValuesFunc
+	testConverter(t, converter, true, desiredResult, []string{
+		`Parent.createFrom({name: "a", dummy: {something: "x"}}).dummy.something === "x"`,
+		`createParent({name: "a"}).name === "a"`,
+		`Parent.createFrom({name: "a", dummy: {something: "x"}}) instanceof Parent`,
+		`Parent.createFrom({name: "a", dummy: {something: "x"}}).dummy instanceof Dummy`,
+		`Parent.createFrom({name: "a", dummy: {something: "x"}}).clone().dummy instanceof Dummy`,
+	})
+
+	// The classes have only the declarations, they're created by the functions:
+	code, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	assert.NotContains(t, code, "constructor(")
+	assert.NotContains(t, code, "convertValues(a: any, classs: any")
+}
+
+func TestIgnoredEmbeddedStruct(t *testing.T) {
+	t.Parallel()
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+		UpdatedBy string `json:"updated_by"`
+	}
+	type Document struct {
+		Audit `json:"-"`
+		Title string `json:"title"`
+	}
+
+	converter := New().
+		Add(Document{}).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Document {
+    title: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+
+	converter = New().
+		Add(Document{}).
+		WithInheritance(true).
+		WithInterface(true).
+		WithBackupDir("")
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestParseCustomCode(t *testing.T) {
+	t.Parallel()
+	existing := `export class Dummy {
+    something: string;
+    //[Dummy:]
+    hello() {
+        return 1;
+    }
+
+    //[end]
+}`
+	customCode, err := ParseCustomCode(strings.NewReader(existing))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Dummy": "    hello() {\n        return 1;\n    }"}, customCode)
+
+	converter := New().
+		Add(Dummy{}).
+		WithConstructor(false).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	var buf bytes.Buffer
+	assert.Nil(t, converter.ConvertToWriter(&buf, customCode))
+	assert.True(t, strings.HasSuffix(buf.String(), existing+"\n"), buf.String())
+}
+
+func TestMethodSignatures(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Named
+		Name string `json:"name"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		AddMethodSignature("Person", "greet(other: Person): string").
+		AddMethodSignature("Named", "getName(): string").
+		WithImplementInterfaces(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Named {
+    getName(): string;
+}
+export interface Person extends Named {
+    name: string;
+    greet(other: Person): string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestTransformBack(t *testing.T) {
+	t.Parallel()
+	type Event struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags" ts_type:"Set<string>" ts_transform:"new Set(__VALUE__)" ts_transform_back:"Array.from(__VALUE__)"`
+	}
+
+	converter := New().
+		Add(Event{}).
+		WithToJSON(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Event {
+    name: string;
+    tags: Set<string>;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.tags = new Set(source["tags"]);
+    }
+
+    toJSON(): any {
+        return {
+            "name": this.name,
+            "tags": Array.from(this.tags),
+        };
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`JSON.stringify(new Event({name: "e", tags: ["a", "a", "b"]})) === '{"name":"e","tags":["a","b"]}'`,
+	})
+}
+
+func TestFieldOrder(t *testing.T) {
+	t.Parallel()
+	type Ordered struct {
+		Zeta  string `json:"zeta"`
+		Alpha int    `json:"alpha"`
+		Mid   bool   `json:"mid"`
+	}
+
+	for order, desiredResult := range map[string]string{
+		FieldOrderDeclaration: `export interface Ordered {
+    zeta: string;
+    alpha: number;
+    mid: boolean;
+}`,
+		FieldOrderReverse: `export interface Ordered {
+    mid: boolean;
+    alpha: number;
+    zeta: string;
+}`,
+		FieldOrderAlphabetical: `export interface Ordered {
+    alpha: number;
+    mid: boolean;
+    zeta: string;
+}`,
+	} {
+		converter := New().
+			Add(Ordered{}).
+			WithFieldOrder(order).
+			WithInterface(true).
+			WithBackupDir("")
+		testConverter(t, converter, true, desiredResult, nil)
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	t.Parallel()
+	type Sorted struct {
+		Zeta  string `json:"zeta"`
+		Alpha int    `json:"alpha" ts_name:"omega"`
+		Mid   bool   `json:"mid"`
+	}
+
+	converter := New().
+		Add(Sorted{}).
+		WithSortFields(true).
+		WithConstructor(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("")
+
+	desiredResult := `export class Sorted {
+    mid: boolean;
+    omega: number;
+    zeta: string;
+
+    static createFrom(source: any = {}) {
+        return new Sorted(source);
+    }
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.mid = source["mid"];
+        this.omega = source["alpha"];
+        this.zeta = source["zeta"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`Sorted.createFrom({zeta: "z", alpha: 1, mid: true}).omega === 1`,
+	})
+	// The same as the alphabetical field order:
+	sorted, err := converter.Convert(nil)
+	assert.Nil(t, err)
+	alphabetical, err := New().
+		Add(Sorted{}).
+		WithFieldOrder(FieldOrderAlphabetical).
+		WithConstructor(true).
+		WithCreateFromMethod(true).
+		WithBackupDir("").
+		Convert(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, sorted, alphabetical)
+}
+
+func TestOptionalChaining(t *testing.T) {
+	t.Parallel()
+	type Child struct {
+		Dummy Dummy `json:"dummy"`
+	}
+	type Parent struct {
+		Child Child `json:"child"`
+	}
+
+	converter := New().
+		Add(Parent{}).
+		WithOptionalChaining(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source?.["something"];
+    }
+}
+export class Child {
+    dummy: Dummy;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.dummy = this.convertValues(source?.["dummy"], Dummy);
+    }
+
+	` + tsConvertValuesFunc + `
+}
+export class Parent {
+    child: Child;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.child = this.convertValues(source?.["child"], Child);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Parent(null as any).child === undefined`,
+		`new Child(null as any).dummy === undefined`,
+		`new Parent({child: {dummy: {something: "x"}}}).child.dummy.something === "x"`,
+	})
+
+	// Only the access of the JSON fields is changed, not the transformations:
+	type Person struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name" ts_transform:"__VALUE__ || __SOURCE__[\"name\"]"`
+	}
+	converter = New().
+		Add(Person{}).
+		WithOptionalChaining(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+	desiredResult = `export class Person {
+    name: string;
+    full_name: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source?.["name"];
+        this.full_name = source?.["full_name"] || source["name"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestFalseForOmittedBools(t *testing.T) {
+	t.Parallel()
+	type Flags struct {
+		Enabled  bool  `json:"enabled,omitempty"`
+		Required bool  `json:"required"`
+		Pointer  *bool `json:"pointer,omitempty"`
+	}
+
+	converter := New().
+		Add(Flags{}).
+		WithFalseForOmittedBools(true).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Flags {
+    enabled?: boolean;
+    required: boolean;
+    pointer?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.enabled = source["enabled"] || false;
+        this.required = source["required"];
+        this.pointer = source["pointer"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Flags({}).enabled === false`,
+		`new Flags({enabled: true}).enabled === true`,
+		`new Flags({}).pointer === undefined`,
+	})
+}
+
+func TestFieldNames(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Name    string  `json:"name"`
+		Count   int     `json:"count"`
+		Address Address `json:"address"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		WithFieldNames(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Address {
+    duration: number;
+    text?: string;
+}
+export const AddressFields = ["duration", "text"] as const;
+export interface Person {
+    name: string;
+    count: number;
+    address: Address;
+}
+export const PersonFields = ["name", "count", "address"] as const;`
+	testConverter(t, converter, true, desiredResult, []string{
+		`PersonFields.join(",") === "name,count,address"`,
+	})
+}
+
+func TestDefaultValues(t *testing.T) {
+	t.Parallel()
+	type Counter struct {
+		Count int      `json:"count" ts_default:"10"`
+		Tags  []string `json:"tags" ts_default:"[]"`
+		Unit  string   `json:"unit" ts_default:"pcs"`
+	}
+
+	converter := New().
+		Add(Counter{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Counter {
+    count: number = 10;
+    tags: string[] = [];
+    unit: string = "pcs";
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.count = source["count"] ?? 10;
+        this.tags = source["tags"] ?? [];
+        this.unit = source["unit"] ?? "pcs";
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Counter({}).count === 10`,
+		`new Counter({count: 0}).count === 0`,
+		`new Counter({}).tags.length === 0`,
+		`new Counter({tags: ["a"]}).tags[0] === "a"`,
+		`new Counter({}).unit === "pcs"`,
+	})
+
+	// No initializers in interfaces:
+	converter = New().
+		Add(Counter{}).
+		WithInterface(true).
+		WithBackupDir("")
+	desiredResult = `export interface Counter {
+    count: number;
+    tags: string[];
+    unit: string;
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestNestedMaps(t *testing.T) {
+	t.Parallel()
+	type Nested struct {
+		Counts     []map[string]int                `json:"counts"`
+		ByGroup    map[string]map[string]Dummy     `json:"by_group"`
+		Timelines  map[string][]map[string]Dummy   `json:"timelines"`
+		SliceOfMap []map[string]map[string][]Dummy `json:"slice_of_map"`
+	}
+
+	converter := New().
+		Add(Nested{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Nested {
+    counts: {[key: string]: number}[];
+    by_group: {[key: string]: {[key: string]: Dummy}};
+    timelines: {[key: string]: {[key: string]: Dummy}[]};
+    slice_of_map: {[key: string]: {[key: string]: Dummy[]}}[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.counts = source["counts"];
+        this.by_group = source["by_group"] ? Object.keys(source["by_group"]).reduce((m1: any, k1: string) => { m1[k1] = this.convertValues(source["by_group"][k1], Dummy, true); return m1; }, {}) : source["by_group"];
+        this.timelines = source["timelines"] ? Object.keys(source["timelines"]).reduce((m1: any, k1: string) => { m1[k1] = this.convertValues(source["timelines"][k1], Dummy, true); return m1; }, {}) : source["timelines"];
+        this.slice_of_map = source["slice_of_map"] ? source["slice_of_map"].map((v1: any) => v1 ? Object.keys(v1).reduce((m2: any, k2: string) => { m2[k2] = this.convertValues(v1[k2], Dummy, true); return m2; }, {}) : v1) : source["slice_of_map"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Nested({counts: [{a: 1}]}).counts[0]["a"] === 1`,
+		`new Nested({by_group: {a: {b: {something: "x"}}}}).by_group["a"]["b"] instanceof Dummy`,
+		`new Nested({timelines: {a: [{b: {something: "x"}}]}}).timelines["a"][0]["b"] instanceof Dummy`,
+		`new Nested({slice_of_map: [{a: {b: [{something: "x"}]}}]}).slice_of_map[0]["a"]["b"][0] instanceof Dummy`,
+		`new Nested({}).by_group === undefined`,
+	})
+}
+
+func TestPatchTypes(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		Add(Dummy{}).
+		WithPatchTypes(true).
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Address {
+    duration: number;
+    text?: string;
+}
+export interface Person {
+    name: string;
+    address: Address;
+}
+export type PersonPatch = Partial<Person>;
+export interface Dummy {
+    something: string;
+}
+export type DummyPatch = Partial<Dummy>;`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(({name: "x"}) as PersonPatch).name === "x"`,
+	})
+}
+
+func TestResponseEnvelope(t *testing.T) {
+	t.Parallel()
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	converter := New().
+		Add(Person{}).
+		WithResponseEnvelope("{data: __TYPE__; error?: string}").
+		WithInterface(true).
+		WithBackupDir("")
+
+	desiredResult := `export interface Person {
+    name: string;
+}
+export type PersonResponse = {data: Person; error?: string};`
+	testConverter(t, converter, true, desiredResult, []string{
+		`(({data: {name: "x"}}) as PersonResponse).data.name === "x"`,
+	})
+
+	typeScriptCode, err := New().
+		Add(Person{}).
+		Add(Dummy{}).
+		WithResponseEnvelope("ApiResponse").
+		WithPatchTypes(true).
+		WithBackupDir("").
+		Convert(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, typeScriptCode, "\nexport type PersonPatch = Partial<Person>;\nexport type PersonResponse = ApiResponse<Person>;\n")
+	assert.Contains(t, typeScriptCode, "\nexport type DummyResponse = ApiResponse<Dummy>;")
+}
+
+func TestSlicesOfPointers(t *testing.T) {
+	t.Parallel()
+	type Container struct {
+		Items []*Dummy   `json:"items"`
+		Grid  [][]*Dummy `json:"grid"`
+		Names []*string  `json:"names"`
+	}
+
+	converter := New().
+		Add(Container{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Container {
+    items: Dummy[];
+    grid: Dummy[][];
+    names: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.items = this.convertValues(source["items"], Dummy);
+        this.grid = this.convertValues(source["grid"], Dummy);
+        this.names = source["names"];
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	// Null elements stay null:
+	jsn, err := json.Marshal(Container{Items: []*Dummy{nil, {Something: "x"}}, Grid: [][]*Dummy{{nil, {}}}})
+	assert.Nil(t, err)
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Container(` + string(jsn) + `).items[0] === null`,
+		`new Container(` + string(jsn) + `).items[1] instanceof Dummy`,
+		`new Container(` + string(jsn) + `).grid[0][0] === null`,
+		`new Container(` + string(jsn) + `).grid[0][1] instanceof Dummy`,
+	})
+
+	converter = New().
+		Add(Container{}).
+		WithNullableElements(true).
+		WithInterface(true).
+		WithBackupDir("")
+	desiredResult = `export interface Dummy {
+    something: string;
+}
+export interface Container {
+    items: (Dummy | null)[];
+    grid: (Dummy | null)[][];
+    names: (string | null)[];
+}`
+	testConverter(t, converter, true, desiredResult, nil)
+}
+
+func TestCreateFromSwitch(t *testing.T) {
+	t.Parallel()
+	type Settings struct {
+		Version int    `json:"version"`
+		Theme   string `json:"theme"`
+	}
+
+	converter := New().
+		Add(Settings{}).
+		AddCreateFromSwitch("Settings", "version", map[string]string{
+			"1":       `source = {...source, theme: source["color"] === "black" ? "dark" : "light"};`,
+			"default": "if (!source[\"theme\"]) source = {...source, theme: \"light\"};",
+		}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Settings {
+    version: number;
+    theme: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        switch (source["version"]) {
+            case 1:
+                source = {...source, theme: source["color"] === "black" ? "dark" : "light"};
+                break;
+            default:
+                if (!source["theme"]) source = {...source, theme: "light"};
+                break;
+        }
+        this.version = source["version"];
+        this.theme = source["theme"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Settings({version: 1, color: "black"}).theme === "dark"`,
+		`new Settings({version: 1, color: "white"}).theme === "light"`,
+		`new Settings({version: 2, theme: "dark"}).theme === "dark"`,
+		`new Settings({version: 2}).theme === "light"`,
+	})
+}
+
+func TestOmitemptyWithTSType(t *testing.T) {
+	t.Parallel()
+	type Measurement struct {
+		Value string  `json:"value,omitempty" ts_type:"Custom"`
+		Unit  *string `json:"unit" ts_type:"Custom"`
+	}
+
+	converter := New().
+		Add(Measurement{}).
+		AddImport("type Custom = string;").
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `type Custom = string;
+
+export class Measurement {
+    value?: Custom;
+    unit?: Custom;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.value = source["value"];
+        this.unit = source["unit"];
+    }
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Measurement({value: "1"}).value === "1"`,
+		`new Measurement({}).value === undefined`,
+	})
+}
+
+func TestMapsOfSlices(t *testing.T) {
+	t.Parallel()
+	type Index struct {
+		Words   map[string][]string `json:"words"`
+		Dummies map[string][]Dummy  `json:"dummies"`
+	}
+
+	converter := New().
+		Add(Index{}).
+		WithConstructor(true).
+		WithCreateFromMethod(false).
+		WithBackupDir("")
+
+	desiredResult := `export class Dummy {
+    something: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.something = source["something"];
+    }
+}
+export class Index {
+    words: {[key: string]: string[]};
+    dummies: {[key: string]: Dummy[]};
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.words = source["words"];
+        this.dummies = this.convertValues(source["dummies"], Dummy, true);
+    }
+
+	` + tsConvertValuesFunc + `
+}`
+	testConverter(t, converter, true, desiredResult, []string{
+		`new Index({words: {a: ["x", "y"]}}).words["a"][1] === "y"`,
+		`new Index({dummies: {a: [{something: "x"}]}}).dummies["a"][0] instanceof Dummy`,
+	})
+}
@@ -1,11 +1,364 @@
 package typescriptify
 
-import "strings"
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
 
-func indentLines(str string, i int) string {
+// qualifiedTypeName returns the type name with the full package path.
+func qualifiedTypeName(typ reflect.Type) string {
+	if typ.PkgPath() == "" {
+		return typ.String()
+	}
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+// jsDoc formats the (possibly multiline) text as a JSDoc comment.
+func jsDoc(text, indent string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	result := indent + "/**\n"
+	for _, line := range lines {
+		result += strings.TrimRight(fmt.Sprintf("%s * %s", indent, strings.TrimSpace(line)), " ") + "\n"
+	}
+	return result + indent + " */\n"
+}
+
+// trimBlankLines removes leading and trailing lines containing only whitespace, but leaves the
+// indentation of the remaining lines intact.
+func trimBlankLines(str string) string {
 	lines := strings.Split(str, "\n")
-	for n := range lines {
-		lines[n] = strings.Repeat("\t", i) + lines[n]
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
 	}
 	return strings.Join(lines, "\n")
 }
+
+// unsupportedKind returns the kind of the type (or of its elements) which can't be represented in TypeScript.
+func unsupportedKind(typ reflect.Type) (reflect.Kind, bool) {
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return typ.Kind(), true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return unsupportedKind(typ.Elem())
+	}
+	return reflect.Invalid, false
+}
+
+// arrayElem unwraps (possibly nested) slices, arrays and pointers to them, and returns the element type and the
+// number of array dimensions.
+func arrayElem(typ reflect.Type) (reflect.Type, int) {
+	arrayDepth := 0
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr:
+			typ = typ.Elem()
+		case reflect.Slice, reflect.Array:
+			if isByteSlice(typ) {
+				return typ, arrayDepth
+			}
+			typ = typ.Elem()
+			arrayDepth++
+		default:
+			return typ, arrayDepth
+		}
+	}
+}
+
+// arrayLengths returns the lengths of the (nested) arrays in the type (0 for slices), in the same order as arrayElem()
+// unwraps them.
+func arrayLengths(typ reflect.Type) []int {
+	var lengths []int
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr:
+			typ = typ.Elem()
+		case reflect.Slice:
+			if isByteSlice(typ) {
+				return lengths
+			}
+			lengths = append(lengths, 0)
+			typ = typ.Elem()
+		case reflect.Array:
+			lengths = append(lengths, typ.Len())
+			typ = typ.Elem()
+		default:
+			return lengths
+		}
+	}
+}
+
+// countMaps returns the number of (nested) maps in the type, i.e. 2 for `map[string][]map[string]int`.
+func countMaps(typ reflect.Type) int {
+	count := 0
+	for {
+		switch typ.Kind() {
+		case reflect.Map:
+			count++
+			typ = typ.Elem()
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+		default:
+			return count
+		}
+	}
+}
+
+// hasPointerElements checks if the elements of the (innermost) slice or array in the type are pointers.
+func hasPointerElements(typ reflect.Type) bool {
+	pointerElements := false
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr:
+			typ = typ.Elem()
+		case reflect.Slice, reflect.Array:
+			if isByteSlice(typ) {
+				return false
+			}
+			typ = typ.Elem()
+			pointerElements = typ.Kind() == reflect.Ptr
+		default:
+			return pointerElements
+		}
+	}
+}
+
+// arrayType returns the TypeScript type of an (arrayDepth dimensional) array of elem.
+func arrayType(elem string, arrayDepth int, readonly bool) string {
+	for i := 0; i < arrayDepth; i++ {
+		if readonly {
+			elem = "ReadonlyArray<" + elem + ">"
+		} else {
+			elem += "[]"
+		}
+	}
+	return elem
+}
+
+// tupleType returns the TypeScript tuple type with length elems (i.e. `[number, number, number]`).
+func tupleType(elem string, length int, readonly bool) string {
+	elems := make([]string, length)
+	for n := range elems {
+		elems[n] = elem
+	}
+	tuple := "[" + strings.Join(elems, ", ") + "]"
+	if readonly {
+		tuple = "readonly " + tuple
+	}
+	return tuple
+}
+
+// elementsTransform returns the transformation (with __VALUE__) of every element in (arrayDepth dimensional) arrays,
+// elemFormat is the format of the element transformation. Nil slices are serialized as null, and if guardElements
+// is true, the elements can be null, too.
+func elementsTransform(arrayDepth int, guardElements bool, elemFormat string) string {
+	transform := fmt.Sprintf(elemFormat, fmt.Sprintf("v%d", arrayDepth))
+	if guardElements {
+		transform = fmt.Sprintf("v%d ? %s : v%d", arrayDepth, transform, arrayDepth)
+	}
+	for i := arrayDepth; i > 0; i-- {
+		transform = fmt.Sprintf("v%d ? v%d.map((v%d: any) => %s) : v%d", i-1, i-1, i, transform, i-1)
+	}
+	return strings.ReplaceAll(transform, "v0", "__VALUE__")
+}
+
+// zeroValue returns the TypeScript code for the default (zero or empty) value of the type.
+func zeroValue(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "0"
+	case reflect.String:
+		return `""`
+	case reflect.Slice:
+		if isByteSlice(typ) {
+			return `""`
+		}
+		return "[]"
+	case reflect.Array:
+		return "[]"
+	case reflect.Map, reflect.Struct:
+		return "{}"
+	}
+	return ""
+}
+
+// defaultValue returns the TypeScript code of a ts_default value, string values are quoted (unless already quoted).
+func defaultValue(value string, kind reflect.Kind) string {
+	if value == "" || kind != reflect.String || strings.IndexAny(value[:1], "\"'`") == 0 {
+		return value
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// isByteSlice checks if the type is a []byte, which is encoded as a base64 JSON string.
+func isByteSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+// containedStruct unwraps pointers, slices, arrays and map values until a struct is found. The second result is true
+// if the struct is contained in a map.
+func containedStruct(typ reflect.Type) (reflect.Type, bool) {
+	inMap := false
+	for {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+		case reflect.Map:
+			typ = typ.Elem()
+			inMap = true
+		case reflect.Struct:
+			return typ, inMap
+		default:
+			return nil, false
+		}
+	}
+}
+
+// isByteArray checks if the type is a fixed size byte array (i.e. `[16]byte`).
+func isByteArray(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8
+}
+
+// jsArray returns the JavaScript array literal of the strings.
+func jsArray(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// withContainerShapes replaces the custom containers (declared with ManageContainerType()) in the type, its elements
+// and map values with their shapes.
+func withContainerShapes(typ reflect.Type, shapes map[reflect.Type]reflect.Type) reflect.Type {
+	if shape, found := shapes[typ]; found {
+		return shape
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if elem := withContainerShapes(typ.Elem(), shapes); elem != typ.Elem() {
+			return reflect.PtrTo(elem)
+		}
+	case reflect.Slice:
+		if elem := withContainerShapes(typ.Elem(), shapes); elem != typ.Elem() {
+			return reflect.SliceOf(elem)
+		}
+	case reflect.Array:
+		if elem := withContainerShapes(typ.Elem(), shapes); elem != typ.Elem() {
+			return reflect.ArrayOf(typ.Len(), elem)
+		}
+	case reflect.Map:
+		if elem := withContainerShapes(typ.Elem(), shapes); elem != typ.Elem() {
+			return reflect.MapOf(typ.Key(), elem)
+		}
+	}
+	return typ
+}
+
+var typeNamePartRegexp = regexp.MustCompile(`[\w./\-~]+`)
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// separateTopLevelStatements adds blank lines between the top-level statements (classes, interfaces, types,...) of the
+// code, which are indented with indent (i.e. in a namespace). Only the first line (and the closing line) of a statement
+// isn't indented deeper, and comments above a statement are kept together with it.
+func separateTopLevelStatements(code, indent string) string {
+	lines := strings.Split(code, "\n")
+	var result []string
+	for n, line := range lines {
+		if n > 0 && isStatementStart(line, indent) {
+			previous, topLevel := topLevelLine(lines[n-1], indent)
+			if previous != "" && topLevel && !strings.HasPrefix(previous, "/") && !strings.HasSuffix(previous, "*/") {
+				result = append(result, "")
+			}
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+func isStatementStart(line, indent string) bool {
+	line, topLevel := topLevelLine(line, indent)
+	return line != "" && topLevel && !strings.HasPrefix(line, "}")
+}
+
+// topLevelLine returns the line without the indent, and if it's a top-level line (not indented deeper).
+func topLevelLine(line, indent string) (string, bool) {
+	if !strings.HasPrefix(line, indent) {
+		return line, false
+	}
+	line = strings.TrimPrefix(line, indent)
+	return line, !startsWithWhitespace(line)
+}
+
+func startsWithWhitespace(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// isIdentifier checks if the name is a valid TypeScript identifier, i.e. can be used as a property name without quotes.
+func isIdentifier(name string) bool {
+	return identifierRegexp.MatchString(name)
+}
+
+// propertyKey returns the property name usable in declarations and object literals, quoted if not an identifier
+// (`"content-type"`).
+func propertyKey(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// propertyAccess returns the property access expression, `obj.name` or `obj["content-type"]`.
+func propertyAccess(obj, name string) string {
+	if isIdentifier(name) {
+		return obj + "." + name
+	}
+	return fmt.Sprintf("%s[%q]", obj, name)
+}
+
+// typeName returns the type name usable in TypeScript. Generic type instantiations like
+// `Response[example.com/models.User]` are converted to `ResponseUser`.
+func typeName(typ reflect.Type) string {
+	name := typ.Name()
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	parts := typeNamePartRegexp.FindAllString(name, -1)
+	for n := range parts {
+		if lastDot := strings.LastIndex(parts[n], "."); lastDot >= 0 {
+			parts[n] = parts[n][lastDot+1:]
+		}
+		if n > 0 && len(parts[n]) > 0 {
+			parts[n] = strings.ToUpper(parts[n][:1]) + parts[n][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// structName returns the type name, or the name synthesized for an anonymous struct (empty if none).
+func structName(typ reflect.Type, anonymousNames map[reflect.Type]string) string {
+	if name := typeName(typ); name != "" {
+		return name
+	}
+	return anonymousNames[typ]
+}
+
+// stripTypeNameSuffix removes the suffix from the type name, unless the name would be empty.
+func stripTypeNameSuffix(name, suffix string) string {
+	if stripped := strings.TrimSuffix(name, suffix); stripped != "" {
+		return stripped
+	}
+	return name
+}